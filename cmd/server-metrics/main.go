@@ -48,6 +48,20 @@ func run() error {
 	flags.Bool("devel", false, "print discovered metrics and exit")
 	flags.String("bind-address", "127.0.0.1:9101", "address to bind to")
 	flags.Bool("no-network-collector", false, "disable network collector")
+	flags.String("classifier-config", "", "path to cgroup classification rules configuration")
+	flags.Bool("split-vm-threads", false, "classify vcpu and emulator threads of libvirt VMs as separate services")
+	flags.StringSlice("aggregate-containers", nil, "aggregate containers into one service at the specified levels (pod, compose)")
+	flags.Bool("temporary-containers-by-image", false, "classify temporary containers by their image instead of one shared service")
+	flags.String("containerd-endpoint", containers.DefaultContainerdEndpoint, "containerd socket to resolve nerdctl containers with")
+	flags.StringSlice("cri-endpoint", containers.DefaultCRIEndpoints, "CRI runtime sockets to resolve Kubernetes containers with")
+	flags.Bool("detailed-memory-stat", false, "collect detailed memory usage breakdown for services")
+	flags.Bool("file-io", false, "collect file-level I/O of services (including page cache, sockets and pipes)")
+	flags.Bool("sched-stat", false, "collect scheduler statistics of services' threads")
+	flags.Bool("file-descriptors", false, "collect file descriptors usage of services")
+	flags.Bool("network-namespaces", false, "collect network traffic of services running in their own network namespaces")
+	flags.Bool("sockets", false, "collect listening sockets and connections of services")
+	flags.Int("top-processes", 0, "collect CPU and memory usage of top N processes of each service")
+	flags.Duration("smaps-interval", 0, "collect proportional memory usage of services with the specified interval (it's expensive)")
 
 	return cmd.Execute()
 }
@@ -70,6 +84,100 @@ func execute(cmd *cobra.Command) error {
 		return err
 	}
 
+	classifierConfigPath, err := flags.GetString("classifier-config")
+	if err != nil {
+		return err
+	}
+
+	classifierConfig := &cgroupclassifier.Config{}
+	if classifierConfigPath != "" {
+		if classifierConfig, err = cgroupclassifier.LoadConfig(classifierConfigPath); err != nil {
+			return err
+		}
+	}
+
+	splitVMThreads, err := flags.GetBool("split-vm-threads")
+	if err != nil {
+		return err
+	} else if splitVMThreads {
+		classifierConfig.SplitVMThreads = true
+	}
+
+	aggregateContainers, err := flags.GetStringSlice("aggregate-containers")
+	if err != nil {
+		return err
+	}
+	for _, value := range aggregateContainers {
+		aggregation, err := cgroupclassifier.ParseContainerAggregation(value)
+		if err != nil {
+			return err
+		}
+		classifierConfig.AggregateContainers = append(classifierConfig.AggregateContainers, aggregation)
+	}
+
+	temporaryContainersByImage, err := flags.GetBool("temporary-containers-by-image")
+	if err != nil {
+		return err
+	} else if temporaryContainersByImage {
+		classifierConfig.TemporaryContainersByImage = true
+	}
+
+	containerdEndpoint, err := flags.GetString("containerd-endpoint")
+	if err != nil {
+		return err
+	}
+
+	criEndpoints, err := flags.GetStringSlice("cri-endpoint")
+	if err != nil {
+		return err
+	}
+
+	var cgroupsConfig cgroupscollector.Config
+
+	cgroupsConfig.DetailedMemoryStat, err = flags.GetBool("detailed-memory-stat")
+	if err != nil {
+		return err
+	}
+
+	cgroupsConfig.FileIO, err = flags.GetBool("file-io")
+	if err != nil {
+		return err
+	}
+
+	cgroupsConfig.SchedStat, err = flags.GetBool("sched-stat")
+	if err != nil {
+		return err
+	}
+
+	cgroupsConfig.FileDescriptors, err = flags.GetBool("file-descriptors")
+	if err != nil {
+		return err
+	}
+
+	cgroupsConfig.NetworkNamespaces, err = flags.GetBool("network-namespaces")
+	if err != nil {
+		return err
+	}
+
+	cgroupsConfig.Sockets, err = flags.GetBool("sockets")
+	if err != nil {
+		return err
+	}
+
+	cgroupsConfig.TopProcesses, err = flags.GetInt("top-processes")
+	if err != nil {
+		return err
+	} else if cgroupsConfig.TopProcesses < 0 {
+		return fmt.Errorf("Invalid number of top processes: %d", cgroupsConfig.TopProcesses)
+	}
+
+	cgroupsConfig.SmapsInterval, err = flags.GetDuration("smaps-interval")
+	if err != nil {
+		return err
+	} else if cgroupsConfig.SmapsInterval < 0 {
+		return fmt.Errorf("Invalid smaps collection interval: %s", cgroupsConfig.SmapsInterval)
+	}
+
 	logLevel := zapcore.InfoLevel
 	if develMode {
 		logLevel = zapcore.DebugLevel
@@ -137,16 +245,65 @@ func execute(cmd *cobra.Command) error {
 		}
 	}()
 
+	userDockerResolver := containers.NewUserDockerResolver()
+	defer func() {
+		if err := userDockerResolver.Close(); err != nil {
+			logging.L(ctx).Errorf("Failed to close user Docker resolver: %s.", err)
+		}
+	}()
+
+	userPodmanResolver := containers.NewUserPodmanResolver()
+	defer func() {
+		if err := userPodmanResolver.Close(); err != nil {
+			logging.L(ctx).Errorf("Failed to close user Podman resolver: %s.", err)
+		}
+	}()
+
+	containerdResolver := containers.NewContainerdResolver(containerdEndpoint)
+	defer func() {
+		if err := containerdResolver.Close(); err != nil {
+			logging.L(ctx).Errorf("Failed to close containerd resolver: %s.", err)
+		}
+	}()
+
+	criResolver := containers.NewCRIResolver(criEndpoints)
+	defer func() {
+		if err := criResolver.Close(); err != nil {
+			logging.L(ctx).Errorf("Failed to close CRI resolver: %s.", err)
+		}
+	}()
+
 	var maxRaceRetries, maxActiveRaces int
 	if !develMode {
 		maxRaceRetries = 2
 		maxActiveRaces = 10
 	}
 
+	cgroupsHierarchy, err := cgroups.Init()
+	if err != nil {
+		return fmt.Errorf("Failed to detect cgroup hierarchy: %w", err)
+	}
+	logging.L(ctx).Debugf("Using %s cgroup hierarchy at %s.", cgroupsHierarchy.Version(), cgroupsHierarchy.Root())
+
 	raceController := cgroups.NewRaceController(logger, maxRaceRetries, maxActiveRaces)
-	cgroupClassifier := cgroupclassifier.New(users.NewResolver(), dockerResolver, podmanResolver)
+	cgroupClassifier, err := cgroupclassifier.NewWithConfig(classifierConfig, users.NewResolver(), map[string]containers.Resolver{
+		"docker":     dockerResolver,
+		"podman":     podmanResolver,
+		"containerd": containerdResolver,
+		"cri":        criResolver,
+	}, map[string]containers.UserResolver{
+		"docker": userDockerResolver,
+		"podman": userPodmanResolver,
+	})
+	if err != nil {
+		return fmt.Errorf("Invalid classifier configuration: %w", err)
+	}
+
+	cgroupsCollector, err := cgroupscollector.NewCollector(logger, cgroupClassifier, raceController, cgroupsConfig)
+	if err != nil {
+		return err
+	}
 
-	cgroupsCollector := cgroupscollector.NewCollector(logger, cgroupClassifier, raceController)
 	if err := register(cgroupsCollector); err != nil {
 		return err
 	}
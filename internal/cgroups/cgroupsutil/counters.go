@@ -0,0 +1,150 @@
+package cgroupsutil
+
+import (
+	"context"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+)
+
+// TaskCounters accumulates counters of processes (or threads) into monotonic per-group counters. Per-task counters are
+// lost when the task exits, so we track each task and account only its usage increase between collections.
+//
+// The counters are tracked per cgroup rather than per service, because several cgroups may be aggregated into one
+// service.
+type TaskCounters struct {
+	name   string
+	groups map[string]*groupCounters
+}
+
+type groupCounters struct {
+	tasks     map[int][]uint64
+	parents   map[int]int
+	total     []uint64
+	collected bool
+}
+
+func NewTaskCounters(name string) *TaskCounters {
+	return &TaskCounters{
+		name:   name,
+		groups: make(map[string]*groupCounters),
+	}
+}
+
+func (c *TaskCounters) Pre() {
+	for _, state := range c.groups {
+		state.collected = false
+	}
+}
+
+func (c *TaskCounters) Post(ctx context.Context) {
+	for group, state := range c.groups {
+		if !state.collected {
+			logging.L(ctx).Debugf("%s: %q group hasn't been collected. Dropping its state.", c.name, group)
+			delete(c.groups, group)
+		}
+	}
+}
+
+// Update accounts the current tasks counters and returns the group total counters
+func (c *TaskCounters) Update(group string, tasks map[int][]uint64) []uint64 {
+	return c.update(group, tasks, nil)
+}
+
+// UpdateProcesses is like Update, but is intended for counters to which the kernel adds the counters of reaped children
+// (like /proc/$pid/io ones). The last observed counters of the exited children are subtracted from the usage increase
+// of their nearest observed ancestor to not account them twice.
+//
+// The correction is an approximation: the parent is the one observed during the previous collection, so the children
+// which have been reparented since then are subtracted from the wrong process.
+func (c *TaskCounters) UpdateProcesses(group string, processes map[int][]uint64, parents map[int]int) []uint64 {
+	return c.update(group, processes, parents)
+}
+
+func (c *TaskCounters) update(group string, tasks map[int][]uint64, parents map[int]int) []uint64 {
+	state, ok := c.groups[group]
+	if !ok {
+		state = &groupCounters{}
+		c.groups[group] = state
+	}
+
+	var reaped map[int][]uint64
+	if parents != nil {
+		reaped = state.reapedChildren(tasks)
+	}
+
+	for pid, counters := range tasks {
+		if state.total == nil {
+			state.total = make([]uint64, len(counters))
+		}
+
+		// New tasks (including the ones moved from other groups) are accounted with all their usage
+		prevCounters := state.tasks[pid]
+		reapedCounters := reaped[pid]
+
+		for index, current := range counters {
+			var prev uint64
+			if prevCounters != nil {
+				prev = prevCounters[index]
+			}
+
+			// A counter decrease means that PID has been reused by another task
+			if current < prev {
+				state.total[index] += current
+				continue
+			}
+
+			increase := current - prev
+			if reapedCounters != nil {
+				increase -= min(increase, reapedCounters[index])
+			}
+			state.total[index] += increase
+		}
+	}
+
+	state.tasks = tasks
+	state.parents = parents
+	state.collected = true
+
+	return state.total
+}
+
+// Returns the last observed counters of the exited tasks grouped by their nearest ancestor which is still alive
+func (s *groupCounters) reapedChildren(tasks map[int][]uint64) map[int][]uint64 {
+	reaped := make(map[int][]uint64)
+
+	for pid, counters := range s.tasks {
+		if _, ok := tasks[pid]; ok {
+			continue
+		}
+
+		// Limit the walk by the number of tasks to not hang on a PID reuse cycle
+		ancestor, found := pid, false
+		for range len(s.parents) {
+			parent, ok := s.parents[ancestor]
+			if !ok {
+				break
+			}
+
+			ancestor = parent
+			if _, ok := tasks[ancestor]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		total, ok := reaped[ancestor]
+		if !ok {
+			total = make([]uint64, len(counters))
+			reaped[ancestor] = total
+		}
+
+		for index, value := range counters {
+			total[index] += value
+		}
+	}
+
+	return reaped
+}
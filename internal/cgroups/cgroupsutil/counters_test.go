@@ -0,0 +1,50 @@
+package cgroupsutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskCounters(t *testing.T) {
+	counters := NewTaskCounters("test")
+
+	require.Equal(t, []uint64{3, 30}, counters.Update("/system.slice/nginx.service", map[int][]uint64{
+		1: {1, 10},
+		2: {2, 20},
+	}))
+
+	// The second process has exited, the third one has been started
+	require.Equal(t, []uint64{7, 70}, counters.Update("/system.slice/nginx.service", map[int][]uint64{
+		1: {2, 20},
+		3: {3, 30},
+	}))
+
+	// The first process PID has been reused
+	require.Equal(t, []uint64{8, 80}, counters.Update("/system.slice/nginx.service", map[int][]uint64{
+		1: {1, 10},
+		3: {3, 30},
+	}))
+}
+
+func TestTaskCountersWithReapedChildren(t *testing.T) {
+	counters := NewTaskCounters("test")
+
+	require.Equal(t, []uint64{6}, counters.UpdateProcesses("/system.slice/nginx.service", map[int][]uint64{
+		1: {1},
+		2: {2},
+		3: {3},
+	}, map[int]int{
+		1: 0,
+		2: 1,
+		3: 2,
+	}))
+
+	// The second and the third processes have exited and have been reaped: the first process counters include all their
+	// usage now. Both the first and the third processes have done one more operation since the previous collection.
+	require.Equal(t, []uint64{8}, counters.UpdateProcesses("/system.slice/nginx.service", map[int][]uint64{
+		1: {2 + 2 + 4},
+	}, map[int]int{
+		1: 0,
+	}))
+}
@@ -10,6 +10,12 @@ import (
 	"github.com/KonishchevDmitry/server-metrics/internal/util"
 )
 
+// iocost and io.latency statistics contain non-integer values: cost.vrate=100.00, depth=max
+var nonIntegerKeys = map[string]bool{
+	"cost.vrate": true,
+	"depth":      true,
+}
+
 func ReadNamedStat(group *cgroups.Group, name string) (map[string]Stat, bool, error) {
 	var stats map[string]Stat
 	exists, err := group.ReadProperty(name, func(file io.Reader) (err error) {
@@ -26,12 +32,13 @@ func parseNamedStat(propertyName string, reader io.Reader) (map[string]Stat, err
 		var nameRead bool
 
 		stat := make(map[string]int64)
+		other := make(map[string]string)
 		lineTokens := strings.Split(line, " ")
 
 		for _, lineToken := range lineTokens {
 			tokens := strings.Split(lineToken, "=")
 
-			if len(tokens) == 1 && len(stat) == 0 {
+			if len(tokens) == 1 && len(stat) == 0 && len(other) == 0 {
 				name := tokens[0]
 				nameRead = true
 
@@ -47,8 +54,9 @@ func parseNamedStat(propertyName string, reader io.Reader) (map[string]Stat, err
 				}
 
 				stats[name] = Stat{
-					name: propertyName,
-					stat: stat,
+					name:  propertyName,
+					stat:  stat,
+					other: other,
 				}
 				continue
 			}
@@ -57,20 +65,23 @@ func parseNamedStat(propertyName string, reader io.Reader) (map[string]Stat, err
 				return fmt.Errorf("Got an unexpected stat line: %q", line)
 			}
 
-			value, err := strconv.ParseInt(tokens[1], 10, 64)
-			if err != nil {
-				return fmt.Errorf("Got an unexpected stat line: %q", line)
-			}
-
 			key := tokens[0]
 			if _, ok := stat[key]; ok {
 				return fmt.Errorf("Got a duplicated %q key", key)
+			} else if _, ok := other[key]; ok {
+				return fmt.Errorf("Got a duplicated %q key", key)
 			}
 
-			stat[key] = value
+			if value, err := strconv.ParseInt(tokens[1], 10, 64); err == nil {
+				stat[key] = value
+			} else if nonIntegerKeys[key] && tokens[1] != "" {
+				other[key] = tokens[1]
+			} else {
+				return fmt.Errorf("Got an unexpected stat line: %q", line)
+			}
 		}
 
-		if len(stat) == 0 {
+		if len(stat) == 0 && len(other) == 0 {
 			return fmt.Errorf("Got an unexpected stat line: %q", line)
 		}
 
@@ -28,3 +28,34 @@ func TestParseNamedStat(t *testing.T) {
 	_, ok := stats["6:0"]
 	require.False(t, ok)
 }
+
+func TestParseNamedStatWithQoS(t *testing.T) {
+	stats, err := parseNamedStat("io.stat", strings.NewReader(heredoc.Doc(`
+		8:16 rbytes=2504615424 wbytes=12477784576 rios=71571 wios=232169 dbytes=0 dios=0 cost.vrate=135.52 cost.usage=4032012 cost.wait=1045 cost.indebt=0 cost.indelay=0
+		8:0 rbytes=4348810240 wbytes=12477784064 rios=103424 wios=232614 dbytes=0 dios=0 depth=max avg_lat=1130 win=200
+	`)))
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	stat := stats["8:16"]
+	require.Equal(t, int64(4032012), stat.stat["cost.usage"])
+
+	vrate, ok := stat.LookupFloat("cost.vrate")
+	require.True(t, ok)
+	require.Equal(t, 135.52, vrate)
+
+	stat = stats["8:0"]
+	_, ok = stat.LookupFloat("depth")
+	require.False(t, ok)
+
+	avgLatency, ok := stat.LookupFloat("avg_lat")
+	require.True(t, ok)
+	require.Equal(t, float64(1130), avgLatency)
+}
+
+func TestParseNamedStatWithUnexpectedNonInteger(t *testing.T) {
+	_, err := parseNamedStat("io.stat", strings.NewReader(heredoc.Doc(`
+		8:16 rbytes=2504615424 wbytes=1.5 rios=71571 wios=232169 dbytes=0 dios=0
+	`)))
+	require.Error(t, err)
+}
@@ -11,8 +11,9 @@ import (
 )
 
 type Stat struct {
-	name string
-	stat map[string]int64
+	name  string
+	stat  map[string]int64
+	other map[string]string // Non-integer values of the known keys like "max" or "100.00"
 }
 
 func (s *Stat) Get(name string) (int64, error) {
@@ -23,6 +24,24 @@ func (s *Stat) Get(name string) (int64, error) {
 	return value, nil
 }
 
+func (s *Stat) Lookup(name string) (int64, bool) {
+	value, ok := s.stat[name]
+	return value, ok
+}
+
+func (s *Stat) LookupFloat(name string) (float64, bool) {
+	if value, ok := s.stat[name]; ok {
+		return float64(value), true
+	}
+
+	value, err := strconv.ParseFloat(s.other[name], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
+
 func ReadStat(group *cgroups.Group, name string) (Stat, bool, error) {
 	stat := Stat{name: name}
 
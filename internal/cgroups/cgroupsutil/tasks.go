@@ -0,0 +1,39 @@
+package cgroupsutil
+
+import (
+	"context"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+)
+
+// TaskErrors tracks errors of per-task /proc reads. A single task may be inaccessible (for example, /proc/$pid/fd of a
+// process owned by another user or of a setuid process), so such tasks are skipped and the group collection fails only
+// when no task could be read at all.
+type TaskErrors struct {
+	name string
+	read int
+	err  error
+}
+
+func NewTaskErrors(name string) *TaskErrors {
+	return &TaskErrors{name: name}
+}
+
+// Read registers a successfully read task
+func (e *TaskErrors) Read() {
+	e.read++
+}
+
+// Failed registers a task which couldn't be read
+func (e *TaskErrors) Failed(ctx context.Context, pid int, err error) {
+	logging.L(ctx).Debugf("%s: Failed to read %d task statistics: %s.", e.name, pid, err)
+	e.err = err
+}
+
+// Err returns an error if all tasks have failed to be read
+func (e *TaskErrors) Err() error {
+	if e.read != 0 {
+		return nil
+	}
+	return e.err
+}
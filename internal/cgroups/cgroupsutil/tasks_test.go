@@ -0,0 +1,26 @@
+package cgroupsutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTaskErrors(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zap.NewNop().Sugar())
+	err := errors.New("permission denied")
+
+	taskErrors := NewTaskErrors("test")
+	require.NoError(t, taskErrors.Err())
+
+	taskErrors.Failed(ctx, 1, err)
+	require.ErrorIs(t, taskErrors.Err(), err)
+
+	taskErrors.Read()
+	taskErrors.Failed(ctx, 2, err)
+	require.NoError(t, taskErrors.Err())
+}
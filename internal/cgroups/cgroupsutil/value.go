@@ -0,0 +1,68 @@
+package cgroupsutil
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/samber/mo"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+)
+
+func ReadValue(group *cgroups.Group, name string) (int64, bool, error) {
+	var value int64
+
+	exists, err := group.ReadProperty(name, func(file io.Reader) (err error) {
+		value, err = parseValue(file)
+		return
+	})
+
+	return value, exists, err
+}
+
+// ReadLimit reads a single value property which may be set to "max" (no limit)
+func ReadLimit(group *cgroups.Group, name string) (mo.Option[int64], bool, error) {
+	var limit mo.Option[int64]
+
+	exists, err := group.ReadProperty(name, func(file io.Reader) (err error) {
+		limit, err = parseLimit(file)
+		return
+	})
+
+	return limit, exists, err
+}
+
+func parseValue(reader io.Reader) (int64, error) {
+	limit, err := parseLimit(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := limit.Get()
+	if !ok {
+		return 0, fmt.Errorf("Got an unexpected value: %q", "max")
+	}
+
+	return value, nil
+}
+
+func parseLimit(reader io.Reader) (mo.Option[int64], error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return mo.None[int64](), err
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return mo.None[int64](), nil
+	}
+
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return mo.None[int64](), fmt.Errorf("Got an unexpected value: %q", value)
+	}
+
+	return mo.Some(limit), nil
+}
@@ -0,0 +1,22 @@
+package cgroupsutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := parseLimit(strings.NewReader("max\n"))
+	require.NoError(t, err)
+	require.Equal(t, mo.None[int64](), limit)
+
+	limit, err = parseLimit(strings.NewReader("4294967296\n"))
+	require.NoError(t, err)
+	require.Equal(t, mo.Some[int64](4294967296), limit)
+
+	_, err = parseValue(strings.NewReader("max\n"))
+	require.Error(t, err)
+}
@@ -3,10 +3,8 @@ package classifier
 import (
 	"context"
 	"fmt"
-	"path"
-	"regexp"
+	"maps"
 	"strconv"
-	"strings"
 
 	"github.com/samber/mo"
 
@@ -17,288 +15,219 @@ import (
 type Classification struct {
 	Service        string
 	TotalExcluding mo.Option[[]string]
-}
 
-type Classifier struct {
-	users  users.Resolver
-	docker containers.Resolver
-	podman containers.Resolver
-}
+	// All group children should be excluded from the total and classified separately
+	ExcludeChildren bool
 
-func New(users users.Resolver, docker containers.Resolver, podman containers.Resolver) *Classifier {
-	return &Classifier{
-		users:  users,
-		docker: docker,
-		podman: podman,
-	}
-}
-
-var (
-	systemSlicePathRegex = regexp.MustCompile(`^/system\.slice(/system-[^/]+\.slice)?$`)
+	// The service may be shared by several cgroups which usage should be summed
+	Aggregated bool
 
-	podmanBuilderPathRegex     = regexp.MustCompile(`^(?:/system\.slice|/user\.slice/user-(\d+)\.slice/user@\d+\.service/app\.slice)?/(?:crun-)?buildah-[^/]+$`)
-	podmanContainerPathRegex   = regexp.MustCompile(`^/machine\.slice/libpod(-conmon)?-([^/]+)\.scope$`)
-	podmanHealthcheckPathRegex = regexp.MustCompile(`^/(?:system\.slice|user\.slice/user-(\d+)\.slice/user@\d+\.service/app\.slice)/([0-9a-f]{64})-[0-9a-f]{16}\.service$`)
-
-	userSliceNameRegex = regexp.MustCompile(`^user-(\d+)\.slice$`)
-	userSlicePathRegex = regexp.MustCompile(`^/user\.slice(/user-(\d+)\.slice(/user@\d+\.service(/(?:app|session)\.slice(?:/(?:app|session)-[^/]+\.slice)?)?)?)?$`)
-)
+	// The container the cgroup belongs to
+	Container mo.Option[Container]
+}
 
-// TODO(konishchev): Need to rewrite all this mess to something maintainable
-func (c *Classifier) ClassifySlice(ctx context.Context, name string) (Classification, bool, error) {
-	var err error
+type Container struct {
+	Runtime string
+	containers.Container
+}
 
-	name = strings.ReplaceAll(name, `\x2d`, `-`)
-	system := classifyContext{slice: "system"}
+type Classifier struct {
+	rules            []*Rule
+	aggregation      []ContainerAggregation
+	temporaryByImage bool
+	users            users.Resolver
+	containers       map[string]containers.Resolver
+	userContainers   map[string]containers.UserResolver
+}
 
-	if name == "/" {
-		return system.classify("kernel")
+func New(
+	users users.Resolver, docker containers.Resolver, podman containers.Resolver,
+	userDocker containers.UserResolver, userPodman containers.UserResolver,
+) *Classifier {
+	// Default configuration has no custom rules, so it can't fail the validation
+	classifier, err := NewWithConfig(&Config{}, users, map[string]containers.Resolver{
+		"docker": docker,
+		"podman": podman,
+	}, map[string]containers.UserResolver{
+		"docker": userDocker,
+		"podman": userPodman,
+	})
+	if err != nil {
+		panic(err)
 	}
+	return classifier
+}
 
-	parent, child := path.Split(name)
-	parent = path.Clean(parent)
-
-	if match := podmanBuilderPathRegex.FindStringSubmatch(name); len(match) != 0 {
-		var slice = system
-		if uidMatch := match[1]; uidMatch != "" {
-			slice, err = c.getUserContext(uidMatch)
-			if err != nil {
-				return Classification{}, false, err
-			}
-		}
-		return slice.classifyTotal("podman-builder")
-	} else if parent == "/" {
-		if child == "init.scope" {
-			return system.classify("init")
-		}
-		return c.classifySupplementaryChild(system, child)
-	} else if match := podmanContainerPathRegex.FindStringSubmatch(name); len(match) != 0 {
-		id, suffix := match[2], ""
-		if match[1] != "" {
-			suffix = "/supervisor"
-		}
-
-		var service = "podman-containers"
-		if container, err := c.podman.Resolve(ctx, id); err != nil {
-			return Classification{}, false, err
-		} else if !container.Temporary {
-			service = container.Name
+// NewWithConfig creates a classifier with the specified rules configuration and container resolvers referenced by
+// the rules by their runtime name. User container resolvers are used for rootless containers.
+//
+// Container runtimes of the custom rules are validated against the resolvers. Default rules reference all supported
+// runtimes and the caller may omit the ones it doesn't need, so they are checked only during classification.
+func NewWithConfig(
+	config *Config, users users.Resolver,
+	containers map[string]containers.Resolver, userContainers map[string]containers.UserResolver,
+) (*Classifier, error) {
+	for index, rule := range config.Rules {
+		if rule.Container == nil {
+			continue
 		}
 
-		return system.classifyTotal(service + suffix)
-	} else if match := podmanHealthcheckPathRegex.FindStringSubmatch(name); len(match) != 0 {
-		var (
-			slice        = system
-			service      = "podman-containers"
-			uidMatch, id = match[1], match[2]
-		)
-
-		if uidMatch != "" {
-			slice, err = c.getUserContext(uidMatch)
-			if err != nil {
-				return Classification{}, false, err
-			}
-
-			// At this time we don't support user containers resolving
+		var ok bool
+		if runtime := rule.Container.Runtime; rule.Container.Rootless {
+			_, ok = userContainers[runtime]
 		} else {
-			if container, err := c.podman.Resolve(ctx, id); err != nil {
-				return Classification{}, false, err
-			} else if !container.Temporary {
-				service = container.Name
-			}
+			_, ok = containers[runtime]
 		}
 
-		return slice.classifyTotal(service + "/healthcheck")
-	} else if match := systemSlicePathRegex.FindStringSubmatch(parent); len(match) != 0 {
-		// /system.slice/*
-		// /system.slice/system-*.slice/*
-		if classification, ok, err := c.classifyServiceSliceChild(ctx, system, child); err != nil || ok {
-			return classification, ok, err
-		}
-		return c.classifySupplementaryChild(system, child)
-	} else if match := userSlicePathRegex.FindStringSubmatch(parent); len(match) != 0 {
-		uidString := match[2]
-
-		// /user.slice/*
-		if match[1] == "" {
-			nameMatch := userSliceNameRegex.FindStringSubmatch(child)
-			if len(nameMatch) == 0 {
-				return Classification{}, false, nil
+		if !ok {
+			kind := "container"
+			if rule.Container.Rootless {
+				kind = "rootless container"
 			}
-			uidString = nameMatch[1]
-		}
-
-		user, err := c.getUserContext(uidString)
-		if err != nil {
-			return Classification{}, false, err
-		}
-
-		systemdUserServiceName := fmt.Sprintf("user@%s.service", uidString)
-
-		// /user.slice/user-1000.slice
-		if match[1] == "" {
-			// The group contains:
-			// * user@1000.service - systemd user session
-			// * session-*.scope - each ssh/mosh connection is assigned to a session
-			return user.classifyTotal("sessions", systemdUserServiceName)
+			return nil, fmt.Errorf("Invalid rule #%d: unsupported %s runtime: %q", index+1, kind, rule.Container.Runtime)
 		}
+	}
 
-		// /user.slice/user-1000.slice/*
-		if match[3] == "" {
-			if child != systemdUserServiceName {
-				return Classification{}, false, nil
-			}
-
-			// user@1000.service contains:
-			// * init.scope - systemd
-			// * app.slice - services
-			// * session.slice:
-			//   * dbus-broker.service
-			// * tmux-spawn-*.scope – each tmux window runs in a separate scope
-			// * user.slice with podmain containers:
-			//   * libpod-*
-			//   * libpod-conmon-*
-			//   * podman-pause-*
-			//
-			// user@1000.service is expected to have no processes, but when user session is being started systemd is
-			// placed here first and only then is being moved to init.scope
-
-			return Classification{
-				Service:        user.user,
-				TotalExcluding: mo.Some([]string{"app.slice", "init.scope"}),
-			}, true, nil
-		}
+	return &Classifier{
+		rules:            config.rules(),
+		aggregation:      config.AggregateContainers,
+		temporaryByImage: config.TemporaryContainersByImage,
+		users:            users,
+		containers:       containers,
+		userContainers:   userContainers,
+	}, nil
+}
 
-		// /user.slice/user-1000.slice/user@1000.service/*
-		if match[4] == "" {
-			if child == "init.scope" {
-				return user.classify("init")
-			}
-			return Classification{}, false, nil
-		}
+func (c *Classifier) ClassifySlice(ctx context.Context, name string) (Classification, bool, error) {
+	name = unescapePath(name)
 
-		// /user.slice/user-1000.slice/user@1000.service/app.slice/*
-		// /user.slice/user-1000.slice/user@1000.service/app.slice/app-*.slice/*
-		// /user.slice/user-1000.slice/user@1000.service/app.slice/snap.*.*-*.scope
-		// /user.slice/user-1000.slice/user@1000.service/session.slice/*
-		// /user.slice/user-1000.slice/user@1000.service/session.slice/session-*.slice/*
-		if classification, ok, err := c.classifyServiceSliceChild(ctx, user, child); err != nil || ok {
-			return classification, ok, err
+	for _, rule := range c.rules {
+		if variables, ok := rule.match(name); ok {
+			return c.classify(ctx, rule, variables)
 		}
-		return c.classifySupplementaryChild(user, child)
-	} else {
-		return Classification{}, false, nil
 	}
-}
 
-func (c *Classifier) classifySupplementaryChild(context classifyContext, name string) (
-	Classification, bool, error,
-) {
-	if strings.HasSuffix(name, ".mount") || strings.HasSuffix(name, ".socket") {
-		return context.classify(name)
-	}
 	return Classification{}, false, nil
 }
 
-const uuidRegex = `[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`
+func (c *Classifier) classify(ctx context.Context, rule *Rule, variables map[string]string) (Classification, bool, error) {
+	var uid int
 
-var (
-	dbusActivationRegex = regexp.MustCompile(`^\d+\.\d+-(.+)\.slice$`)
-	snapScopeNameRegex  = regexp.MustCompile(`^snap\.[^.]+\.([^.]+)-` + uuidRegex + `\.scope$`)
-)
+	if rule.UID != "" {
+		var (
+			user string
+			err  error
+		)
 
-func (c *Classifier) classifyServiceSliceChild(ctx context.Context, context classifyContext, name string) (
-	Classification, bool, error,
-) {
-	// DBus creates a unique unit for each service activation:
-	// /system.slice/system-dbus\x2d:1.4\x2dorg.fedoraproject.SetroubleshootPrivileged.slice/dbus-:1.4-org.fedoraproject.SetroubleshootPrivileged@21.service
-	dbusActivationPrefix := fmt.Sprintf(`%s-dbus-:`, context.slice)
-	if strings.HasPrefix(name, dbusActivationPrefix) {
-		if match := dbusActivationRegex.FindStringSubmatch(name[len(dbusActivationPrefix):]); len(match) != 0 {
-			return context.classifyTotal("dbus:" + match[1])
+		uid, user, err = c.getUser(expand(rule.UID, variables))
+		if err != nil {
+			return Classification{}, false, err
 		}
+		variables["user"] = user
 	}
 
-	serviceSuffix := ".service"
-	if strings.HasSuffix(name, serviceSuffix) {
-		service := trim("", name, serviceSuffix)
+	var (
+		aggregated bool
+		container  mo.Option[Container]
+	)
 
-		// We can have here:
-		// * A regular systemd unit
-		// * systemd-udevd with non-standard cgroups configuration
-		// * A Podman container with `runtime` and `libpod-payload-$id` groups
-		return context.classifyTotal(service)
-	}
-
-	dockerPrefix, dockerSuffix := "docker-", ".scope"
-	if strings.HasPrefix(name, dockerPrefix) && strings.HasSuffix(name, dockerSuffix) {
-		containerID := trim(dockerPrefix, name, dockerSuffix)
-
-		container, err := c.docker.Resolve(ctx, containerID)
+	if rule.Container != nil {
+		info, err := c.getContainer(ctx, rule.Container, uid, variables)
 		if err != nil {
 			return Classification{}, false, err
 		}
 
-		service := container.Name
-		if container.Temporary {
-			service = "docker-containers"
-		}
+		var name string
+		name, aggregated = c.getContainerService(rule.Container, info, variables)
+		variables["container"] = name
 
-		return context.classify(service)
+		container = mo.Some(Container{
+			Runtime:   rule.Container.Runtime,
+			Container: info,
+		})
 	}
 
-	dockerBuilderPrefix := fmt.Sprintf("%s.slice:docker:", context.slice)
-	if strings.HasPrefix(name, dockerBuilderPrefix) && path.Ext(name[len(dockerBuilderPrefix):]) == "" {
-		return context.classify("docker-builder")
+	classification := Classification{
+		Service:    expand(rule.Service, variables),
+		Aggregated: aggregated,
+		Container:  container,
 	}
 
-	if match := snapScopeNameRegex.FindStringSubmatch(name); len(match) != 0 {
-		return context.classify(match[1])
+	if rule.Split {
+		classification.TotalExcluding = mo.Some[[]string](nil)
+		classification.ExcludeChildren = true
+	} else if rule.Total || len(rule.Exclude) != 0 {
+		var exclude []string
+		for _, name := range rule.Exclude {
+			exclude = append(exclude, expand(name, variables))
+		}
+		classification.TotalExcluding = mo.Some(exclude)
 	}
 
-	return Classification{}, false, nil
+	return classification, true, nil
 }
 
-func (c *Classifier) getUserContext(uidMatch string) (classifyContext, error) {
-	uid, err := strconv.Atoi(uidMatch)
+func (c *Classifier) getUser(uidString string) (int, string, error) {
+	uid, err := strconv.Atoi(uidString)
 	if err != nil {
-		return classifyContext{}, err
+		return 0, "", fmt.Errorf("invalid user ID: %q", uidString)
 	}
 
 	name, err := c.users.Resolve(uid)
 	if err != nil {
-		return classifyContext{}, fmt.Errorf(
+		return 0, "", fmt.Errorf(
 			"unable to resolve %d user ID: %w", uid, err)
 	}
 
-	return classifyContext{
-		slice: "app",
-		user:  name,
-	}, nil
+	return uid, name, nil
 }
 
-type classifyContext struct {
-	slice string
-	user  string
-}
+func (c *Classifier) getContainer(
+	ctx context.Context, rule *ContainerRule, uid int, variables map[string]string,
+) (containers.Container, error) {
+	id := expand(rule.ID, variables)
+
+	if rule.Rootless {
+		resolver, ok := c.userContainers[rule.Runtime]
+		if !ok {
+			return containers.Container{}, fmt.Errorf("unsupported rootless container runtime: %q", rule.Runtime)
+		}
+		return resolver.Resolve(ctx, uid, id)
+	}
 
-func (c classifyContext) classify(service string) (Classification, bool, error) {
-	if c.user != "" {
-		service = fmt.Sprintf("%s/%s", c.user, service)
+	resolver, ok := c.containers[rule.Runtime]
+	if !ok {
+		return containers.Container{}, fmt.Errorf("unsupported container runtime: %q", rule.Runtime)
 	}
-	return Classification{Service: service}, true, nil
+	return resolver.Resolve(ctx, id)
 }
 
-func (c classifyContext) classifyTotal(service string, exclude ...string) (Classification, bool, error) {
-	if c.user != "" {
-		service = fmt.Sprintf("%s/%s", c.user, service)
+// getContainerService returns ${container} value for the resolved container and whether it's aggregated with other
+// containers.
+func (c *Classifier) getContainerService(
+	rule *ContainerRule, container containers.Container, variables map[string]string,
+) (string, bool) {
+	if container.Temporary {
+		if c.temporaryByImage && rule.TemporaryByImage != "" && container.Image != "" {
+			imageVariables := maps.Clone(variables)
+			imageVariables["image"] = container.Image
+			return expand(rule.TemporaryByImage, imageVariables), true
+		}
+		return expand(rule.Temporary, variables), false
+	}
+
+	for _, aggregation := range c.aggregation {
+		switch aggregation {
+		case PodAggregation:
+			if container.Pod != "" {
+				return container.Pod, true
+			}
+		case ComposeAggregation:
+			if container.ComposeProject != "" {
+				return container.ComposeProject + "/" + container.ComposeService, true
+			}
+		}
 	}
-	return Classification{
-		Service:        service,
-		TotalExcluding: mo.Some(exclude),
-	}, true, nil
-}
 
-func trim(prefix string, name string, suffix string) string {
-	return name[len(prefix) : len(name)-len(suffix)]
+	return container.Name, false
 }
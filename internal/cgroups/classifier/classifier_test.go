@@ -34,7 +34,27 @@ func TestClassifier(t *testing.T) {
 		require.NoError(t, podmanResolver.Close())
 	}()
 
-	classifier := New(userResolver, dockerResolver, podmanResolver)
+	userDockerResolver := containers.NewUserResolverMock(map[int]map[string]containers.Container{
+		1000: {
+			"0f8e2d1c3b4a59687a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f": {Name: "postgres"},
+			"9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c": {Temporary: true},
+		},
+	})
+	defer func() {
+		require.NoError(t, userDockerResolver.Close())
+	}()
+
+	userPodmanResolver := containers.NewUserResolverMock(map[int]map[string]containers.Container{
+		1000: {
+			"5ad006cde140386bb880ddf7c8f1881e0446c2f9cd46a2ed446250b09072854e": {Name: "syncthing"},
+			"7e5c2a9d4b3f1e0d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d": {Temporary: true},
+		},
+	})
+	defer func() {
+		require.NoError(t, userPodmanResolver.Close())
+	}()
+
+	classifier := New(userResolver, dockerResolver, podmanResolver, userDockerResolver, userPodmanResolver)
 
 	traverse := mo.None[[]string]()
 	total := func(exclude ...string) mo.Option[[]string] {
@@ -72,21 +92,33 @@ func TestClassifier(t *testing.T) {
 		{"/machine.slice/libpod-conmon-cdbcfe0c9ba72a9908bca0d50f438275178f5e94229ac54e2ea9bd71e70e4134.scope", "server-metrics/supervisor", total()},
 		{"/machine.slice/libpod-dc9145bfa6eeb9f415dea90c2eaabbac6f35e844cfc71f25cf3c4567773a0d83.scope", "podman-containers", total()},
 		{"/machine.slice/libpod-conmon-dc9145bfa6eeb9f415dea90c2eaabbac6f35e844cfc71f25cf3c4567773a0d83.scope", "podman-containers/supervisor", total()},
+		{`/machine.slice/machine-qemu\x2d1\x2dwin\x2d10.scope`, "win-10", total()},
+		{`/machine.slice/machine-qemu\x2dfedora.scope`, "fedora", total()},
+		{`/machine.slice/machine-debian\x2dsid.scope`, "debian-sid", total()},
+		{`/machine.slice/systemd-nspawn@arch\x2dbuild.service`, "arch-build", total()},
 
 		{"/user.slice", "", traverse},
 		{"/user.slice/user-1000.slice", "dmitry/sessions", total("user@1000.service")},
-		{"/user.slice/user-1000.slice/user@1000.service", "dmitry", total("app.slice", "init.scope")},
+		{"/user.slice/user-1000.slice/user@1000.service", "dmitry", total("app.slice", "init.scope", "user.slice")},
 		{"/user.slice/user-1000.slice/user@1000.service/app.slice", "", traverse},
 		{"/user.slice/user-1000.slice/user@1000.service/app.slice/buildah-buildah1525486166", "dmitry/podman-builder", total()},            // remote build
 		{"/user.slice/user-1000.slice/user@1000.service/app.slice/crun-buildah-buildah1059824916.scope", "dmitry/podman-builder", total()}, // local build
 		{"/user.slice/user-1000.slice/user@1000.service/app.slice/dbus.socket", "dmitry/dbus.socket", traverse},
+		{"/user.slice/user-1000.slice/user@1000.service/app.slice/docker.service", "dmitry/docker", total()},
 		{"/user.slice/user-1000.slice/user@1000.service/app.slice/app-vm.slice", "", traverse},
 		{"/user.slice/user-1000.slice/user@1000.service/app.slice/app-vm.slice/vm@linux.service", "dmitry/vm@linux", total()},
 		{"/user.slice/user-1000.slice/user@1000.service/app.slice/ssh-agent.service", "dmitry/ssh-agent", total()},
 		{"/user.slice/user-1000.slice/user@1000.service/app.slice/snap.go.go-345c278e-7032-498e-8348-5c092e5d3623.scope", "dmitry/go", traverse},
 		{"/user.slice/user-1000.slice/user@1000.service/app.slice/snap.shadowsocks-rust.ssserver-6f2a6b45-86b0-43fc-944f-d367b51e6c2f.scope", "dmitry/ssserver", traverse},
-		{"/user.slice/user-1000.slice/user@1000.service/app.slice/5ad006cde140386bb880ddf7c8f1881e0446c2f9cd46a2ed446250b09072854e-6b72c8998348f9c4.service", "dmitry/podman-containers/healthcheck", total()},
+		{"/user.slice/user-1000.slice/user@1000.service/app.slice/5ad006cde140386bb880ddf7c8f1881e0446c2f9cd46a2ed446250b09072854e-6b72c8998348f9c4.service", "dmitry/syncthing/healthcheck", total()},
 		{"/user.slice/user-1000.slice/user@1000.service/init.scope", "dmitry/init", traverse},
+		{"/user.slice/user-1000.slice/user@1000.service/user.slice", "", traverse},
+		{"/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-5ad006cde140386bb880ddf7c8f1881e0446c2f9cd46a2ed446250b09072854e.scope", "dmitry/syncthing", total()},
+		{"/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-conmon-5ad006cde140386bb880ddf7c8f1881e0446c2f9cd46a2ed446250b09072854e.scope", "dmitry/syncthing/supervisor", total()},
+		{"/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-7e5c2a9d4b3f1e0d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d.scope", "dmitry/podman-containers", total()},
+		{"/user.slice/user-1000.slice/user@1000.service/user.slice/podman-pause-3c1f5a2e.scope", "dmitry/podman-pause", total()},
+		{"/user.slice/user-1000.slice/user@1000.service/user.slice/docker-0f8e2d1c3b4a59687a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f.scope", "dmitry/postgres", traverse},
+		{"/user.slice/user-1000.slice/user@1000.service/user.slice/docker-9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c.scope", "dmitry/docker-containers", traverse},
 	} {
 		t.Run(testCase.group, func(t *testing.T) {
 			classification, ok, err := classifier.ClassifySlice(ctx, testCase.group)
@@ -97,3 +129,87 @@ func TestClassifier(t *testing.T) {
 		})
 	}
 }
+
+func TestKubernetesClassifier(t *testing.T) {
+	ctx := context.Background()
+
+	criResolver := containers.NewResolverMock(map[string]containers.Container{
+		"4d8e4b1a0b6a5f3c1e8e1c7b0f2c9d6e5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d": {Name: "default/web-7c5ddbdf54-x2x8k/nginx"},
+		"9a1f2e3d4c5b6a7980a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f607": {Name: "default/web-7c5ddbdf54-x2x8k/sandbox"},
+		"0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c": {Name: "kube-system/coredns-5d78c9869d-4vh2q/coredns"},
+	})
+	defer func() {
+		require.NoError(t, criResolver.Close())
+	}()
+
+	classifier, err := NewWithConfig(&Config{}, users.NewResolverMock(nil), map[string]containers.Resolver{
+		"cri": criResolver,
+	}, nil)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		group   string
+		service string
+	}{
+		{"/kubepods.slice", ""},
+		{"/kubepods.slice/kubepods-burstable.slice", ""},
+		{"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod5f7c7a3e_2b1d_4c1e_9f0e_3b2a1c0d9e8f.slice", ""},
+		{"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod5f7c7a3e_2b1d_4c1e_9f0e_3b2a1c0d9e8f.slice/cri-containerd-4d8e4b1a0b6a5f3c1e8e1c7b0f2c9d6e5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d.scope", "default/web-7c5ddbdf54-x2x8k/nginx"},
+		{"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod5f7c7a3e_2b1d_4c1e_9f0e_3b2a1c0d9e8f.slice/cri-containerd-9a1f2e3d4c5b6a7980a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f607.scope", "default/web-7c5ddbdf54-x2x8k/sandbox"},
+		{"/kubepods.slice/kubepods-pod1e2d3c4b_5a69_4788_9aab_bccddeeff001.slice/crio-0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c.scope", "kube-system/coredns-5d78c9869d-4vh2q/coredns"},
+		{"/kubepods.slice/kubepods-pod1e2d3c4b_5a69_4788_9aab_bccddeeff001.slice/crio-conmon-0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c.scope", "kube-system/coredns-5d78c9869d-4vh2q/coredns/supervisor"},
+		{"/kubepods/besteffort/pod5f7c7a3e-2b1d-4c1e-9f0e-3b2a1c0d9e8f/4d8e4b1a0b6a5f3c1e8e1c7b0f2c9d6e5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d", "default/web-7c5ddbdf54-x2x8k/nginx"},
+	} {
+		t.Run(testCase.group, func(t *testing.T) {
+			classification, ok, err := classifier.ClassifySlice(ctx, testCase.group)
+			require.NoError(t, err)
+			require.Equal(t, testCase.service != "", ok)
+			require.Equal(t, testCase.service, classification.Service)
+			if ok {
+				require.Equal(t, mo.Some[[]string](nil), classification.TotalExcluding)
+			}
+		})
+	}
+}
+
+func TestContainerdClassifier(t *testing.T) {
+	ctx := context.Background()
+
+	containerdResolver := containers.NewResolverMock(map[string]containers.Container{
+		"6f2b8c1d0e9a7b3c5d4e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c": {
+			ID: "6f2b8c1d0e9a7b3c5d4e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c", Name: "minio",
+			Image: "quay.io/minio/minio", ImageTag: "latest",
+		},
+		"1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f809": {Name: "alpine-1a2b3", Temporary: true},
+	})
+	defer func() {
+		require.NoError(t, containerdResolver.Close())
+	}()
+
+	classifier, err := NewWithConfig(&Config{}, users.NewResolverMock(nil), map[string]containers.Resolver{
+		"containerd": containerdResolver,
+	}, nil)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		group   string
+		service string
+		image   string
+	}{
+		{"/system.slice/nerdctl-6f2b8c1d0e9a7b3c5d4e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c.scope", "minio", "quay.io/minio/minio"},
+		{"/system.slice/nerdctl-1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f809.scope", "nerdctl-containers", ""},
+	} {
+		t.Run(testCase.group, func(t *testing.T) {
+			classification, ok, err := classifier.ClassifySlice(ctx, testCase.group)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, testCase.service, classification.Service)
+			require.Equal(t, mo.Some[[]string](nil), classification.TotalExcluding)
+
+			container, ok := classification.Container.Get()
+			require.True(t, ok)
+			require.Equal(t, "containerd", container.Runtime)
+			require.Equal(t, testCase.image, container.Image)
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package classifier
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a classification rules configuration. Rules are matched in order against cgroup paths and the first
+// matching rule determines the classification. Custom rules are matched before the default ones.
+type Config struct {
+	Rules        []*Rule `yaml:"rules"`
+	DefaultRules *bool   `yaml:"default_rules"`
+
+	// Classify vcpu and emulator threads of libvirt virtual machines as separate services
+	SplitVMThreads bool `yaml:"split_vm_threads"`
+
+	// Aggregate containers into one service at the specified levels
+	AggregateContainers []ContainerAggregation `yaml:"aggregate_containers"`
+
+	// Classify temporary containers by their images (see ContainerRule.TemporaryByImage) summing usage of concurrent
+	// instances
+	TemporaryContainersByImage bool `yaml:"temporary_containers_by_image"`
+}
+
+// ContainerAggregation specifies the level at which containers are aggregated into one service summing their usage.
+type ContainerAggregation string
+
+const (
+	// All containers of a pod are classified as the pod
+	PodAggregation ContainerAggregation = "pod"
+
+	// Replicas of a Compose service are classified as "<project>/<service>"
+	ComposeAggregation ContainerAggregation = "compose"
+)
+
+func ParseContainerAggregation(value string) (ContainerAggregation, error) {
+	switch aggregation := ContainerAggregation(value); aggregation {
+	case PodAggregation, ComposeAggregation:
+		return aggregation, nil
+	default:
+		return "", fmt.Errorf("Invalid container aggregation level: %q", value)
+	}
+}
+
+func (a *ContainerAggregation) UnmarshalYAML(node *yaml.Node) error {
+	var value string
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+
+	aggregation, err := ParseContainerAggregation(value)
+	if err != nil {
+		return err
+	}
+
+	*a = aggregation
+	return nil
+}
+
+// Rule classifies the cgroups matching its path regular expression. Service name and other templates may reference
+// named captures of the path regular expression as $name or ${name}, ${user} if uid is specified and ${container}
+// if container is specified.
+type Rule struct {
+	Path string `yaml:"path"`
+
+	// UID template to resolve to ${user}
+	UID string `yaml:"uid"`
+
+	// Container to resolve to ${container}
+	Container *ContainerRule `yaml:"container"`
+
+	Service string `yaml:"service"`
+
+	// Collect total usage of the group excluding the specified children instead of traversing its children
+	Total   bool     `yaml:"total"`
+	Exclude []string `yaml:"exclude"`
+
+	// Collect total usage of the group excluding all its children which are classified separately
+	Split bool `yaml:"split"`
+
+	path *regexp.Regexp
+}
+
+type ContainerRule struct {
+	Runtime string `yaml:"runtime"`
+	ID      string `yaml:"id"`
+
+	// Service name template for temporary containers
+	Temporary string `yaml:"temporary"`
+
+	// Service name template for temporary containers when they are classified by their images. Additionally to the
+	// rule variables may reference ${image} – the container image repository. Temporary template is used if not set.
+	TemporaryByImage string `yaml:"temporary_by_image"`
+
+	// Resolve the container using rootless runtime of the rule user
+	Rootless bool `yaml:"rootless"`
+}
+
+//go:embed default_rules.yaml
+var defaultRulesData []byte
+
+//go:embed vm_threads_rules.yaml
+var vmThreadsRulesData []byte
+
+var (
+	defaultRules   = mustParseRules(defaultRulesData)
+	vmThreadsRules = mustParseRules(vmThreadsRulesData)
+)
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := parseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load %q: %w", path, err)
+	}
+
+	return config, nil
+}
+
+func parseConfig(data []byte) (*Config, error) {
+	var config Config
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+
+	for index, rule := range config.Rules {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("Invalid rule #%d: %w", index+1, err)
+		}
+	}
+
+	return &config, nil
+}
+
+func mustParseRules(data []byte) []*Rule {
+	config, err := parseConfig(data)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid default classification rules: %s", err))
+	}
+	return config.Rules
+}
+
+func (c *Config) rules() []*Rule {
+	if c.DefaultRules != nil && !*c.DefaultRules {
+		return c.Rules
+	}
+
+	rules := c.Rules[:len(c.Rules):len(c.Rules)]
+	if c.SplitVMThreads {
+		rules = append(rules, vmThreadsRules...)
+	}
+
+	return append(rules, defaultRules...)
+}
+
+func (r *Rule) compile() error {
+	if r.Path == "" {
+		return fmt.Errorf("Path is missing")
+	} else if r.Service == "" {
+		return fmt.Errorf("Service is missing")
+	} else if r.Split && len(r.Exclude) != 0 {
+		return fmt.Errorf("Split rule can't have excluded children")
+	}
+
+	path, err := regexp.Compile(r.Path)
+	if err != nil {
+		return err
+	}
+	r.path = path
+
+	variables := make(map[string]struct{})
+	for _, name := range path.SubexpNames() {
+		if name != "" {
+			variables[name] = struct{}{}
+		}
+	}
+
+	validate := func(name string, template string, variables map[string]struct{}) error {
+		var err error
+		os.Expand(template, func(variable string) string {
+			if _, ok := variables[variable]; !ok && err == nil {
+				err = fmt.Errorf("%s references an unknown %q variable", name, variable)
+			}
+			return ""
+		})
+		return err
+	}
+
+	if r.UID != "" {
+		if err := validate("UID", r.UID, variables); err != nil {
+			return err
+		}
+	}
+
+	if container := r.Container; container != nil {
+		if container.Runtime == "" {
+			return fmt.Errorf("Container runtime is missing")
+		} else if container.ID == "" {
+			return fmt.Errorf("Container ID is missing")
+		} else if container.Temporary == "" {
+			return fmt.Errorf("Temporary container service name is missing")
+		} else if container.Rootless && r.UID == "" {
+			return fmt.Errorf("Rootless container requires UID")
+		}
+
+		for name, template := range map[string]string{
+			"Container ID":                     container.ID,
+			"Temporary container service name": container.Temporary,
+		} {
+			if err := validate(name, template, variables); err != nil {
+				return err
+			}
+		}
+
+		if container.TemporaryByImage != "" {
+			imageVariables := maps.Clone(variables)
+			imageVariables["image"] = struct{}{}
+
+			if err := validate("Temporary container service name by image", container.TemporaryByImage, imageVariables); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.UID != "" {
+		variables["user"] = struct{}{}
+	}
+	if r.Container != nil {
+		variables["container"] = struct{}{}
+	}
+
+	if err := validate("Service", r.Service, variables); err != nil {
+		return err
+	}
+
+	for _, exclude := range r.Exclude {
+		if err := validate("Excluded child", exclude, variables); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Rule) match(name string) (map[string]string, bool) {
+	match := r.path.FindStringSubmatch(name)
+	if match == nil {
+		return nil, false
+	}
+
+	variables := make(map[string]string)
+	for index, name := range r.path.SubexpNames() {
+		if name != "" {
+			variables[name] = match[index]
+		}
+	}
+
+	return variables, true
+}
+
+func expand(template string, variables map[string]string) string {
+	return os.Expand(template, func(name string) string {
+		return variables[name]
+	})
+}
@@ -0,0 +1,255 @@
+package classifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/containers"
+	"github.com/KonishchevDmitry/server-metrics/internal/users"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := parseConfig([]byte(`
+rules:
+  - path: '^/custom\.slice/(?P<name>[^/]+)\.scope$'
+    service: custom/$name
+    exclude: [payload]
+`))
+	require.NoError(t, err)
+	require.Len(t, config.Rules, 1)
+	require.Len(t, config.rules(), len(defaultRules)+1)
+
+	config, err = parseConfig([]byte(`aggregate_containers: [pod, compose]`))
+	require.NoError(t, err)
+	require.Equal(t, []ContainerAggregation{PodAggregation, ComposeAggregation}, config.AggregateContainers)
+
+	config, err = parseConfig([]byte(`
+default_rules: false
+rules:
+  - path: '^/$'
+    service: kernel
+`))
+	require.NoError(t, err)
+	require.Len(t, config.rules(), 1)
+
+	for _, invalid := range []string{
+		`rules: [{service: kernel}]`,
+		`rules: [{path: '^/$'}]`,
+		`rules: [{path: '^/($', service: kernel}]`,
+		`rules: [{path: '^/$', service: $name}]`,
+		`rules: [{path: '^/$', service: $user}]`,
+		`rules: [{path: '^/(?P<id>.+)$', service: $container}]`,
+		`rules: [{path: '^/(?P<id>.+)$', service: $container, container: {runtime: docker, id: $id}}]`,
+		`rules: [{path: '^/$', service: kernel, unknown: true}]`,
+		`aggregate_containers: [replica]`,
+		`rules: [{path: '^/(?P<id>.+)$', service: $container, container: {runtime: podman, id: $id, temporary: tmp, rootless: true}}]`,
+		`rules: [{path: '^/(?P<id>.+)$', service: $container, container: {runtime: docker, id: $id, temporary: tmp, temporary_by_image: $tag}}]`,
+		`rules: [{path: '^/$', service: kernel, split: true, exclude: [init.scope]}]`,
+	} {
+		_, err := parseConfig([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	ctx := context.Background()
+
+	config, err := parseConfig([]byte(`
+rules:
+  - path: '^/custom\.slice/runner-(?P<id>[0-9a-f]+)\.scope$'
+    container: {runtime: docker, id: $id, temporary: runners}
+    service: $container
+  - path: '^/custom\.slice/(?P<name>[^/]+)\.scope$'
+    service: custom/$name
+    exclude: [payload]
+  - path: '^/system\.slice/nginx\.service$'
+    service: web
+`))
+	require.NoError(t, err)
+
+	dockerResolver := containers.NewResolverMock(map[string]containers.Container{
+		"1234": {Temporary: true},
+	})
+	defer func() {
+		require.NoError(t, dockerResolver.Close())
+	}()
+
+	classifier, err := NewWithConfig(config, users.NewResolverMock(nil), map[string]containers.Resolver{
+		"docker": dockerResolver,
+	}, nil)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		group          string
+		service        string
+		totalExcluding mo.Option[[]string]
+	}{
+		{"/custom.slice/app.scope", "custom/app", mo.Some([]string{"payload"})},
+		{"/custom.slice/runner-1234.scope", "runners", mo.None[[]string]()},
+		{"/system.slice/nginx.service", "web", mo.None[[]string]()},
+		{"/system.slice/sshd.service", "sshd", mo.Some[[]string](nil)},
+	} {
+		classification, ok, err := classifier.ClassifySlice(ctx, testCase.group)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, testCase.service, classification.Service)
+		require.Equal(t, testCase.totalExcluding, classification.TotalExcluding)
+	}
+}
+
+func TestSplitVMThreads(t *testing.T) {
+	ctx := context.Background()
+
+	classifier, err := NewWithConfig(&Config{SplitVMThreads: true}, users.NewResolverMock(nil), nil, nil)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		group           string
+		service         string
+		totalExcluding  mo.Option[[]string]
+		excludeChildren bool
+	}{
+		{`/machine.slice/machine-qemu\x2d1\x2dwin\x2d10.scope`, "win-10", mo.None[[]string](), false},
+		{`/machine.slice/machine-qemu\x2d1\x2dwin\x2d10.scope/libvirt`, "win-10", mo.Some[[]string](nil), true},
+		{`/machine.slice/machine-qemu\x2d1\x2dwin\x2d10.scope/libvirt/vcpu0`, "win-10/vcpu0", mo.Some[[]string](nil), false},
+		{`/machine.slice/machine-qemu\x2d1\x2dwin\x2d10.scope/emulator`, "win-10/emulator", mo.Some[[]string](nil), false},
+		{`/machine.slice/machine-debian\x2dsid.scope`, "debian-sid", mo.Some[[]string](nil), false},
+	} {
+		t.Run(testCase.group, func(t *testing.T) {
+			classification, ok, err := classifier.ClassifySlice(ctx, testCase.group)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, testCase.service, classification.Service)
+			require.Equal(t, testCase.totalExcluding, classification.TotalExcluding)
+			require.Equal(t, testCase.excludeChildren, classification.ExcludeChildren)
+		})
+	}
+}
+
+func TestContainerAggregation(t *testing.T) {
+	ctx := context.Background()
+
+	podmanResolver := containers.NewResolverMock(map[string]containers.Container{
+		"1111111111111111111111111111111111111111111111111111111111111111": {Name: "nextcloud-app", Pod: "nextcloud"},
+		"2222222222222222222222222222222222222222222222222222222222222222": {Name: "nextcloud-infra", Pod: "nextcloud"},
+		"3333333333333333333333333333333333333333333333333333333333333333": {Name: "mail-web-1", ComposeProject: "mail", ComposeService: "web"},
+		"4444444444444444444444444444444444444444444444444444444444444444": {Name: "mail-web-2", ComposeProject: "mail", ComposeService: "web"},
+		"5555555555555555555555555555555555555555555555555555555555555555": {Name: "grafana"},
+	})
+	defer func() {
+		require.NoError(t, podmanResolver.Close())
+	}()
+
+	for _, testCase := range []struct {
+		aggregation []ContainerAggregation
+		group       string
+		service     string
+		aggregated  bool
+	}{
+		{nil, "/machine.slice/machine-libpod_pod_0123abcd.slice/libpod-1111111111111111111111111111111111111111111111111111111111111111.scope", "nextcloud-app", false},
+		{nil, "/machine.slice/libpod-3333333333333333333333333333333333333333333333333333333333333333.scope", "mail-web-1", false},
+
+		{[]ContainerAggregation{PodAggregation}, "/machine.slice/machine-libpod_pod_0123abcd.slice/libpod-1111111111111111111111111111111111111111111111111111111111111111.scope", "nextcloud", true},
+		{[]ContainerAggregation{PodAggregation}, "/machine.slice/machine-libpod_pod_0123abcd.slice/libpod-2222222222222222222222222222222222222222222222222222222222222222.scope", "nextcloud", true},
+		{[]ContainerAggregation{PodAggregation}, "/machine.slice/machine-libpod_pod_0123abcd.slice/libpod-conmon-2222222222222222222222222222222222222222222222222222222222222222.scope", "nextcloud/supervisor", true},
+		{[]ContainerAggregation{PodAggregation}, "/machine.slice/libpod-3333333333333333333333333333333333333333333333333333333333333333.scope", "mail-web-1", false},
+
+		{[]ContainerAggregation{ComposeAggregation}, "/machine.slice/libpod-3333333333333333333333333333333333333333333333333333333333333333.scope", "mail/web", true},
+		{[]ContainerAggregation{ComposeAggregation}, "/machine.slice/libpod-4444444444444444444444444444444444444444444444444444444444444444.scope", "mail/web", true},
+		{[]ContainerAggregation{ComposeAggregation}, "/machine.slice/libpod-5555555555555555555555555555555555555555555555555555555555555555.scope", "grafana", false},
+	} {
+		t.Run(testCase.group, func(t *testing.T) {
+			classifier, err := NewWithConfig(&Config{AggregateContainers: testCase.aggregation}, users.NewResolverMock(nil), map[string]containers.Resolver{
+				"podman": podmanResolver,
+			}, nil)
+			require.NoError(t, err)
+
+			classification, ok, err := classifier.ClassifySlice(ctx, testCase.group)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, testCase.service, classification.Service)
+			require.Equal(t, testCase.aggregated, classification.Aggregated)
+		})
+	}
+}
+
+func TestTemporaryContainersByImage(t *testing.T) {
+	ctx := context.Background()
+
+	dockerResolver := containers.NewResolverMock(map[string]containers.Container{
+		"1111111111111111111111111111111111111111111111111111111111111111": {Temporary: true, Image: "certbot/certbot", ImageTag: "latest"},
+		"2222222222222222222222222222222222222222222222222222222222222222": {Temporary: true},
+	})
+	defer func() {
+		require.NoError(t, dockerResolver.Close())
+	}()
+
+	podmanResolver := containers.NewResolverMock(map[string]containers.Container{
+		"3333333333333333333333333333333333333333333333333333333333333333": {Temporary: true, Image: "docker.io/library/postgres", ImageTag: "17"},
+	})
+	defer func() {
+		require.NoError(t, podmanResolver.Close())
+	}()
+
+	resolvers := map[string]containers.Resolver{
+		"docker": dockerResolver,
+		"podman": podmanResolver,
+	}
+
+	for _, testCase := range []struct {
+		byImage    bool
+		group      string
+		service    string
+		aggregated bool
+	}{
+		{false, "/system.slice/docker-1111111111111111111111111111111111111111111111111111111111111111.scope", "docker-containers", false},
+		{true, "/system.slice/docker-1111111111111111111111111111111111111111111111111111111111111111.scope", "docker-run/certbot/certbot", true},
+		{true, "/system.slice/docker-2222222222222222222222222222222222222222222222222222222222222222.scope", "docker-containers", false},
+		{true, "/machine.slice/libpod-3333333333333333333333333333333333333333333333333333333333333333.scope", "podman-run/docker.io/library/postgres", true},
+		{true, "/machine.slice/libpod-conmon-3333333333333333333333333333333333333333333333333333333333333333.scope", "podman-run/docker.io/library/postgres/supervisor", true},
+		{false, "/jobs.slice/job-1111111111111111111111111111111111111111111111111111111111111111.scope", "jobs", false},
+		{true, "/jobs.slice/job-1111111111111111111111111111111111111111111111111111111111111111.scope", "jobs/certbot/certbot", true},
+		{true, "/runners.slice/runner-1111111111111111111111111111111111111111111111111111111111111111.scope", "runners", false},
+	} {
+		t.Run(testCase.group, func(t *testing.T) {
+			config, err := parseConfig([]byte(`
+rules:
+  - path: '^/jobs\.slice/job-(?P<id>[0-9a-f]{64})\.scope$'
+    container: {runtime: docker, id: $id, temporary: jobs, temporary_by_image: jobs/$image}
+    service: $container
+  - path: '^/runners\.slice/runner-(?P<id>[0-9a-f]{64})\.scope$'
+    container: {runtime: docker, id: $id, temporary: runners}
+    service: $container
+`))
+			require.NoError(t, err)
+			config.TemporaryContainersByImage = testCase.byImage
+
+			classifier, err := NewWithConfig(config, users.NewResolverMock(nil), resolvers, nil)
+			require.NoError(t, err)
+
+			classification, ok, err := classifier.ClassifySlice(ctx, testCase.group)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, testCase.service, classification.Service)
+			require.Equal(t, testCase.aggregated, classification.Aggregated)
+		})
+	}
+}
+
+func TestUnsupportedContainerRuntime(t *testing.T) {
+	config, err := parseConfig([]byte(`
+rules:
+  - path: '^/system\.slice/lxc-(?P<id>[0-9a-f]+)\.scope$'
+    container: {runtime: lxc, id: $id, temporary: lxc-containers}
+    service: $container
+`))
+	require.NoError(t, err)
+
+	_, err = NewWithConfig(config, users.NewResolverMock(nil), map[string]containers.Resolver{
+		"docker": nil,
+	}, nil)
+	require.EqualError(t, err, `Invalid rule #1: unsupported container runtime: "lxc"`)
+}
@@ -0,0 +1,31 @@
+package classifier
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// unescapePath unescapes systemd unit name escaping (\xHH sequences) in all cgroup path components. Escaped slashes
+// are left as is to preserve the path structure.
+func unescapePath(path string) string {
+	if !strings.Contains(path, `\x`) {
+		return path
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(path))
+
+	for index := 0; index < len(path); index++ {
+		if path[index] == '\\' && index+4 <= len(path) && path[index+1] == 'x' {
+			var char [1]byte
+			if _, err := hex.Decode(char[:], []byte(path[index+2:index+4])); err == nil && char[0] != '/' && char[0] != 0 {
+				buf.WriteByte(char[0])
+				index += 3
+				continue
+			}
+		}
+		buf.WriteByte(path[index])
+	}
+
+	return buf.String()
+}
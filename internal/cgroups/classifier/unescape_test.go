@@ -0,0 +1,19 @@
+package classifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnescapePath(t *testing.T) {
+	for escaped, unescaped := range map[string]string{
+		`/system.slice/nginx.service`:                               `/system.slice/nginx.service`,
+		`/machine.slice/machine-qemu\x2d1\x2dwin\x2d10.scope`:       `/machine.slice/machine-qemu-1-win-10.scope`,
+		`/machine.slice/machine-my\x5fvm\x20\xd0\xb2\xd0\xbc.scope`: "/machine.slice/machine-my_vm вм.scope",
+		`/system.slice/mnt-a\x2fb.mount`:                            `/system.slice/mnt-a\x2fb.mount`,
+		`/system.slice/invalid\xZZ\x2`:                              `/system.slice/invalid\xZZ\x2`,
+	} {
+		require.Equal(t, unescaped, unescapePath(escaped), escaped)
+	}
+}
@@ -12,3 +12,37 @@ type Collector interface {
 	Collect(ctx context.Context, service string, group *Group, exclude []string, metrics chan<- prometheus.Metric) (bool, error)
 	Post(ctx context.Context)
 }
+
+// Aggregation specifies how the metric is aggregated when several cgroups are collected as one service
+type Aggregation int
+
+const (
+	// The metric isn't reported for aggregated services (configuration values, ratios, etc.)
+	NoAggregation Aggregation = iota
+
+	// Values of the cgroups are summed. Counters remain monotonic when some of the cgroups vanish.
+	SumAggregation
+
+	// The max value among the cgroups is taken
+	MaxAggregation
+)
+
+var aggregations = make(map[*prometheus.Desc]Aggregation)
+
+// SumAggregated marks the metric to be summed when several cgroups are collected as one service. Must be called only
+// during package initialization.
+func SumAggregated(desc *prometheus.Desc) *prometheus.Desc {
+	aggregations[desc] = SumAggregation
+	return desc
+}
+
+// MaxAggregated marks the metric to be aggregated by max value when several cgroups are collected as one service. Must
+// be called only during package initialization.
+func MaxAggregated(desc *prometheus.Desc) *prometheus.Desc {
+	aggregations[desc] = MaxAggregation
+	return desc
+}
+
+func GetAggregation(desc *prometheus.Desc) Aggregation {
+	return aggregations[desc]
+}
@@ -0,0 +1,214 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+)
+
+// aggregator merges metrics of several cgroups (members) collected as one service according to their
+// cgroups.Aggregation. Metrics without aggregation are dropped.
+//
+// Summed counters must stay monotonic when members come and go, so the aggregator tracks the last value of each member
+// across collections: when a member vanishes (or its counter is reset), its last value is folded into the base offset
+// of the aggregated counter.
+type aggregator struct {
+	metrics map[string]*aggregatedMetric
+	order   []string // Metrics collected during the current collection
+}
+
+type aggregatedMetric struct {
+	desc        *prometheus.Desc
+	aggregation cgroups.Aggregation
+	metric      *dto.Metric
+
+	base    float64            // The sum of counters of the vanished members
+	members map[string]float64 // Values of the members collected during the current collection
+	prev    map[string]float64 // Counters of the members collected during the previous collection
+}
+
+func newAggregator() *aggregator {
+	return &aggregator{
+		metrics: make(map[string]*aggregatedMetric),
+	}
+}
+
+// member returns a channel which aggregates metrics of the specified member cgroup and a function which must be called
+// when all its metrics are sent
+func (a *aggregator) member(ctx context.Context, group string) (chan<- prometheus.Metric, func()) {
+	metrics := make(chan prometheus.Metric)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for metric := range metrics {
+			if err := a.add(group, metric); err != nil {
+				logging.L(ctx).Errorf("Failed to aggregate metrics: %s.", err)
+			}
+		}
+	}()
+
+	return metrics, func() {
+		close(metrics)
+		<-done
+	}
+}
+
+func (a *aggregator) add(group string, metric prometheus.Metric) error {
+	desc := metric.Desc()
+
+	aggregation := cgroups.GetAggregation(desc)
+	if aggregation == cgroups.NoAggregation {
+		return nil
+	}
+
+	var data dto.Metric
+	if err := metric.Write(&data); err != nil {
+		return err
+	}
+
+	value, err := getValue(&data)
+	if err != nil {
+		return fmt.Errorf("unable to aggregate %s: %w", desc, err)
+	}
+
+	key := metricKey(desc, &data)
+
+	aggregated, ok := a.metrics[key]
+	if !ok {
+		aggregated = &aggregatedMetric{
+			desc:        desc,
+			aggregation: aggregation,
+			prev:        make(map[string]float64),
+		}
+		a.metrics[key] = aggregated
+	} else if (aggregated.metric.Counter != nil) != (data.Counter != nil) {
+		return fmt.Errorf("unable to aggregate %s: metric type has changed", desc)
+	}
+
+	if aggregated.members == nil {
+		aggregated.members = make(map[string]float64)
+		a.order = append(a.order, key)
+	}
+	aggregated.metric = &data
+
+	if current, ok := aggregated.members[group]; ok {
+		value = aggregated.merge(current, value)
+	}
+	aggregated.members[group] = value
+
+	return nil
+}
+
+func (a *aggregator) flush(metrics chan<- prometheus.Metric) {
+	for _, key := range a.order {
+		metrics <- a.metrics[key].flush()
+	}
+
+	for key, metric := range a.metrics {
+		if metric.members == nil {
+			delete(a.metrics, key)
+		} else {
+			metric.members = nil
+		}
+	}
+	a.order = nil
+}
+
+func metricKey(desc *prometheus.Desc, metric *dto.Metric) string {
+	labels := make([]string, 0, len(metric.Label))
+	for _, label := range metric.Label {
+		labels = append(labels, label.GetName()+"="+label.GetValue())
+	}
+	sort.Strings(labels)
+	return desc.String() + "\xff" + strings.Join(labels, "\xff")
+}
+
+func (m *aggregatedMetric) merge(a float64, b float64) float64 {
+	if m.aggregation == cgroups.MaxAggregation {
+		return math.Max(a, b)
+	}
+	return a + b
+}
+
+func (m *aggregatedMetric) flush() prometheus.Metric {
+	var value float64
+	first := true
+
+	for _, current := range m.members {
+		if first {
+			value, first = current, false
+		} else {
+			value = m.merge(value, current)
+		}
+	}
+
+	if m.metric.Counter != nil && m.aggregation == cgroups.SumAggregation {
+		for group, prev := range m.prev {
+			if current, ok := m.members[group]; !ok || current < prev {
+				m.base += prev
+			}
+		}
+
+		clear(m.prev)
+		for group, current := range m.members {
+			m.prev[group] = current
+		}
+
+		value += m.base
+	}
+
+	metric := proto.Clone(m.metric).(*dto.Metric)
+	setValue(metric, value)
+
+	return &constMetric{desc: m.desc, metric: metric}
+}
+
+func getValue(metric *dto.Metric) (float64, error) {
+	switch {
+	case metric.Counter != nil:
+		return metric.Counter.GetValue(), nil
+	case metric.Gauge != nil:
+		return metric.Gauge.GetValue(), nil
+	case metric.Untyped != nil:
+		return metric.Untyped.GetValue(), nil
+	default:
+		return 0, fmt.Errorf("unsupported metric type")
+	}
+}
+
+func setValue(metric *dto.Metric, value float64) {
+	switch {
+	case metric.Counter != nil:
+		metric.Counter.Value = proto.Float64(value)
+	case metric.Gauge != nil:
+		metric.Gauge.Value = proto.Float64(value)
+	case metric.Untyped != nil:
+		metric.Untyped.Value = proto.Float64(value)
+	}
+}
+
+type constMetric struct {
+	desc   *prometheus.Desc
+	metric *dto.Metric
+}
+
+var _ prometheus.Metric = &constMetric{}
+
+func (m *constMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+func (m *constMetric) Write(metric *dto.Metric) error {
+	proto.Merge(metric, m.metric)
+	return nil
+}
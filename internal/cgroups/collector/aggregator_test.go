@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+)
+
+var (
+	testCounterDesc = cgroups.SumAggregated(prometheus.NewDesc("test_counter", "Test counter.", []string{"service", "type"}, nil))
+	testGaugeDesc   = cgroups.SumAggregated(prometheus.NewDesc("test_gauge", "Test gauge.", []string{"service"}, nil))
+	testRatioDesc   = cgroups.MaxAggregated(prometheus.NewDesc("test_ratio", "Test ratio.", []string{"service"}, nil))
+	testConfigDesc  = prometheus.NewDesc("test_config", "Test configuration.", []string{"service"}, nil)
+)
+
+type testMember struct {
+	group  string
+	metric prometheus.Metric
+}
+
+func TestAggregator(t *testing.T) {
+	aggregator := newAggregator()
+
+	require.Equal(t, []float64{4, 3, 0.5, 2}, aggregate(t, aggregator, []testMember{
+		{"a", prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 1, "web", "a")},
+		{"a", prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 1, "web")},
+		{"a", prometheus.MustNewConstMetric(testRatioDesc, prometheus.GaugeValue, 0.5, "web")},
+		{"a", prometheus.MustNewConstMetric(testConfigDesc, prometheus.GaugeValue, 10, "web")},
+		{"a", prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 2, "web", "b")},
+		{"b", prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 3, "web", "a")},
+		{"b", prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 2, "web")},
+		{"b", prometheus.MustNewConstMetric(testRatioDesc, prometheus.GaugeValue, 0.25, "web")},
+		{"b", prometheus.MustNewConstMetric(testConfigDesc, prometheus.GaugeValue, 20, "web")},
+	}))
+
+	require.Error(t, aggregator.add("a", prometheus.MustNewConstMetric(testCounterDesc, prometheus.GaugeValue, 1, "web", "a")))
+}
+
+func TestAggregatorVanishedMember(t *testing.T) {
+	aggregator := newAggregator()
+
+	require.Equal(t, []float64{30, 3}, aggregate(t, aggregator, []testMember{
+		{"a", prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 10, "web", "a")},
+		{"a", prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 1, "web")},
+		{"b", prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 20, "web", "a")},
+		{"b", prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 2, "web")},
+	}))
+
+	// Member b has vanished, and a new member c has appeared
+	require.Equal(t, []float64{36, 4}, aggregate(t, aggregator, []testMember{
+		{"a", prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 15, "web", "a")},
+		{"a", prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 1, "web")},
+		{"c", prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 1, "web", "a")},
+		{"c", prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 3, "web")},
+	}))
+
+	// Member a has been recreated with the same name
+	require.Equal(t, []float64{38, 2}, aggregate(t, aggregator, []testMember{
+		{"a", prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 1, "web", "a")},
+		{"a", prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 1, "web")},
+		{"c", prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 2, "web", "a")},
+		{"c", prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 1, "web")},
+	}))
+}
+
+func aggregate(t *testing.T, aggregator *aggregator, members []testMember) []float64 {
+	for _, member := range members {
+		require.NoError(t, aggregator.add(member.group, member.metric))
+	}
+
+	metrics := make(chan prometheus.Metric, len(members))
+	aggregator.flush(metrics)
+	close(metrics)
+
+	var values []float64
+	for metric := range metrics {
+		var data dto.Metric
+		require.NoError(t, metric.Write(&data))
+
+		if data.Counter != nil {
+			values = append(values, data.Counter.GetValue())
+		} else {
+			values = append(values, data.Gauge.GetValue())
+		}
+	}
+
+	return values
+}
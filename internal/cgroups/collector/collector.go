@@ -4,17 +4,29 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"path"
 	"sync"
+	"time"
 
 	logging "github.com/KonishchevDmitry/go-easy-logging"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/mo"
 	"go.uber.org/zap"
 
 	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
 	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/classifier"
 	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cpu"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/fds"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/fileio"
 	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/io"
 	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/memory"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/netns"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/pids"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/pressure"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/sched"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/smaps"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/sockets"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/topprocs"
 )
 
 type Collector struct {
@@ -24,24 +36,91 @@ type Collector struct {
 	lock       sync.Mutex
 	races      *cgroups.RaceController
 	collectors []cgroups.Collector
+	aggregator *aggregator
 }
 
 var _ prometheus.Collector = &Collector{}
 
-func NewCollector(logger *zap.SugaredLogger, classifier *classifier.Classifier, races *cgroups.RaceController) *Collector {
+type Config struct {
+	DetailedMemoryStat bool
+
+	// Per-process and per-thread collectors which are expensive on hosts with many processes
+	FileIO            bool
+	SchedStat         bool
+	FileDescriptors   bool
+	NetworkNamespaces bool
+	Sockets           bool
+
+	TopProcesses int
+
+	// Zero disables smaps collection
+	SmapsInterval time.Duration
+}
+
+func NewCollector(
+	logger *zap.SugaredLogger, classifier *classifier.Classifier, races *cgroups.RaceController, config Config,
+) (*Collector, error) {
+	cpuCollector, err := cpu.NewCollector(races)
+	if err != nil {
+		return nil, err
+	}
+
+	collectors := []cgroups.Collector{
+		cpuCollector,
+		memory.NewCollector(races, config.DetailedMemoryStat),
+		memory.NewEventsCollector(races),
+		io.NewCollector(races),
+		pressure.NewCollector(),
+		pids.NewCollector(races),
+	}
+
+	if config.FileIO {
+		collectors = append(collectors, fileio.NewCollector())
+	}
+
+	if config.SchedStat {
+		collectors = append(collectors, sched.NewCollector())
+	}
+
+	if config.FileDescriptors {
+		collectors = append(collectors, fds.NewCollector())
+	}
+
+	if config.NetworkNamespaces {
+		collectors = append(collectors, netns.NewCollector())
+	}
+
+	if config.Sockets {
+		socketsCollector, err := sockets.NewCollector()
+		if err != nil {
+			return nil, err
+		}
+		collectors = append(collectors, socketsCollector)
+	}
+
+	if config.TopProcesses != 0 {
+		topProcesses, err := topprocs.NewCollector(config.TopProcesses)
+		if err != nil {
+			return nil, err
+		}
+		collectors = append(collectors, topProcesses)
+	}
+
+	if config.SmapsInterval != 0 {
+		collectors = append(collectors, smaps.NewCollector(config.SmapsInterval))
+	}
+
 	return &Collector{
 		logger:     logger,
 		classifier: classifier,
 		races:      races,
-		collectors: []cgroups.Collector{
-			cpu.NewCollector(races),
-			memory.NewCollector(races),
-			io.NewCollector(races),
-		},
-	}
+		collectors: collectors,
+		aggregator: newAggregator(),
+	}, nil
 }
 
 func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- containerInfoMetric
 	for _, collector := range c.collectors {
 		collector.Describe(descs)
 	}
@@ -60,9 +139,14 @@ func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
 	}
 
 	root := cgroups.NewGroup("/", c.races)
-	services := make(map[string]string)
+	state := &collectionState{
+		services: make(map[string]serviceState),
+		metrics:  metrics,
+	}
+
+	exists, err := c.observe(ctx, root, state)
+	c.aggregator.flush(metrics)
 
-	exists, err := c.observe(ctx, root, services, metrics)
 	if err == nil && !exists {
 		err = fmt.Errorf("%q is not mounted", root.Path())
 	}
@@ -78,9 +162,17 @@ func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
 	c.races.OnCollectionFinished()
 }
 
-func (c *Collector) observe(
-	ctx context.Context, group *cgroups.Group, services map[string]string, metrics chan<- prometheus.Metric,
-) (bool, error) {
+type collectionState struct {
+	services map[string]serviceState
+	metrics  chan<- prometheus.Metric
+}
+
+type serviceState struct {
+	group      string
+	aggregated bool
+}
+
+func (c *Collector) observe(ctx context.Context, group *cgroups.Group, state *collectionState) (bool, error) {
 	classification, classified, err := c.classifier.ClassifySlice(ctx, group.Name)
 	if err != nil {
 		logging.L(ctx).Errorf("Failed to classify %q cgroup: %s.", group.Name, err)
@@ -89,9 +181,32 @@ func (c *Collector) observe(
 
 	var needsCollection bool
 
-	if totalExcluding, ok := classification.TotalExcluding.Get(); ok {
+	if ruleExcluding, ok := classification.TotalExcluding.Get(); ok {
+		// Excluded children may be created on demand (for example, user.slice of user@.service appears only with
+		// rootless containers), so the missing ones are ignored
+		totalExcluding := make([]string, 0, len(ruleExcluding))
+		for _, name := range ruleExcluding {
+			if exists, err := group.Child(name).IsExist(); err != nil {
+				return false, err
+			} else if exists {
+				totalExcluding = append(totalExcluding, name)
+			}
+		}
+
+		if classification.ExcludeChildren {
+			children, exists, err := group.Children()
+			if err != nil || !exists {
+				return exists, err
+			}
+
+			for _, child := range children {
+				totalExcluding = append(totalExcluding, path.Base(child.Name))
+			}
+		}
+		classification.TotalExcluding = mo.Some(totalExcluding)
+
 		for _, name := range totalExcluding {
-			if _, err := c.observe(ctx, group.Child(name), services, metrics); err != nil {
+			if _, err := c.observe(ctx, group.Child(name), state); err != nil {
 				return false, err
 			}
 		}
@@ -118,7 +233,7 @@ func (c *Collector) observe(
 			}
 
 			for _, child := range children {
-				if exists, err := c.observe(ctx, child, services, metrics); err != nil {
+				if exists, err := c.observe(ctx, child, state); err != nil {
 					return false, err
 				} else if !exists {
 					logging.L(ctx).Debugf("%q has been deleted during discovering.", child.Path())
@@ -134,11 +249,22 @@ func (c *Collector) observe(
 		return true, nil
 	}
 
-	if otherGroup, ok := services[classification.Service]; ok {
-		logging.L(ctx).Errorf("Both %q and %q resolve to %q service.", otherGroup, group.Name, classification.Service)
+	if other, ok := state.services[classification.Service]; ok && !(other.aggregated && classification.Aggregated) {
+		logging.L(ctx).Errorf("Both %q and %q resolve to %q service.", other.group, group.Name, classification.Service)
 		return true, nil
 	}
-	services[classification.Service] = group.Name
+	state.services[classification.Service] = serviceState{
+		group:      group.Name,
+		aggregated: classification.Aggregated,
+	}
+
+	// Metrics of the services which may be shared by several cgroups are aggregated
+	metrics := state.metrics
+	if classification.Aggregated {
+		var done func()
+		metrics, done = c.aggregator.member(ctx, group.Name)
+		defer done()
+	}
 
 	if exists, err := c.collect(ctx, classification.Service, group, classification.TotalExcluding.OrEmpty(), metrics); err != nil {
 		logging.L(ctx).Errorf("Failed to collect metrics for %s cgroup: %s.", group.Name, err)
@@ -146,6 +272,11 @@ func (c *Collector) observe(
 		return false, nil
 	}
 
+	if container, ok := classification.Container.Get(); ok {
+		metrics <- prometheus.MustNewConstMetric(containerInfoMetric, prometheus.GaugeValue, 1, classification.Service,
+			container.Runtime, container.ID, container.Image, container.ImageTag, container.SystemdUnit)
+	}
+
 	return true, nil
 }
 
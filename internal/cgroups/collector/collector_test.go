@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/classifier"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/io"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/memory"
+	"github.com/KonishchevDmitry/server-metrics/internal/users"
+)
+
+const (
+	testMemoryStat = "anon 1000\nfile 2000\nkernel_stack 10\npagetables 20\npercpu 30\nslab_unreclaimable 40\nsock 50\nswapcached 0\n"
+	testEvents     = "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\noom_group_kill 0\n"
+)
+
+// libvirt VM with split threads on cgroup v2: vcpu and emulator groups are threaded and have no memory and io
+// controllers
+func TestThreadedGroups(t *testing.T) {
+	vm := `machine.slice/machine-qemu\x2d1\x2dwin\x2d10.scope`
+	threaded := map[string]string{
+		"cgroup.controllers": "cpu pids",
+		"cgroup.procs":       "",
+		"cgroup.type":        "threaded",
+	}
+
+	libvirt := testLeafGroup("1000")
+	libvirt["cgroup.type"] = "domain threaded"
+
+	testCollection(t, map[string]map[string]string{
+		"":                                   testRootGroup(),
+		"machine.slice":                      testDomainGroup(),
+		vm:                                   testDomainGroup(),
+		path.Join(vm, "libvirt"):             libvirt,
+		path.Join(vm, "libvirt", "vcpu0"):    threaded,
+		path.Join(vm, "libvirt", "emulator"): threaded,
+	}, &classifier.Config{SplitVMThreads: true}, "kernel", "win-10")
+}
+
+// user@.service excludes its children which may be missing
+func TestMissingExcludedChildren(t *testing.T) {
+	manager := "user.slice/user-1000.slice/user@1000.service"
+
+	testCollection(t, map[string]map[string]string{
+		"":                               testRootGroup(),
+		"user.slice":                     testDomainGroup(),
+		"user.slice/user-1000.slice":     testLeafGroup(""),
+		manager:                          testLeafGroup(""),
+		path.Join(manager, "app.slice"):  testDomainGroup(),
+		path.Join(manager, "init.scope"): testLeafGroup("1000"),
+	}, &classifier.Config{}, "kernel", "user", "user/init", "user/sessions")
+}
+
+func testRootGroup() map[string]string {
+	return map[string]string{
+		"cgroup.controllers": "cpu io memory pids",
+		"cgroup.procs":       "",
+		"memory.stat":        testMemoryStat,
+		"io.stat":            "",
+	}
+}
+
+func testDomainGroup() map[string]string {
+	return map[string]string{
+		"cgroup.controllers":  "cpu io memory pids",
+		"cgroup.procs":        "",
+		"memory.stat":         testMemoryStat,
+		"memory.swap.current": "0",
+		"memory.events":       testEvents,
+		"memory.events.local": testEvents,
+		"io.stat":             "",
+	}
+}
+
+func testLeafGroup(procs string) map[string]string {
+	group := testDomainGroup()
+	group["cgroup.procs"] = procs
+
+	for name, value := range map[string]string{
+		"memory.current":  "3150",
+		"memory.max":      "max",
+		"memory.high":     "max",
+		"memory.low":      "0",
+		"memory.min":      "0",
+		"memory.swap.max": "max",
+	} {
+		group[name] = value
+	}
+
+	return group
+}
+
+// Runs memory and I/O collection over the emulated cgroup hierarchy ensuring that no errors or races are reported
+func testCollection(t *testing.T, groups map[string]map[string]string, config *classifier.Config, services ...string) {
+	root := t.TempDir()
+
+	for group, files := range groups {
+		groupPath := path.Join(root, group)
+		require.NoError(t, os.MkdirAll(groupPath, 0o755))
+		for name, data := range files {
+			require.NoError(t, os.WriteFile(path.Join(groupPath, name), []byte(data), 0o644))
+		}
+	}
+
+	_, err := cgroups.InitUnified(root)
+	require.NoError(t, err)
+
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core).Sugar()
+
+	classifier, err := classifier.NewWithConfig(config, users.NewResolverMock(map[int]string{1000: "user"}), nil, nil)
+	require.NoError(t, err)
+
+	// Don't tolerate any races to catch reading of missing files
+	races := cgroups.NewRaceController(logger, 0, 0)
+	collector := &Collector{
+		logger:     logger,
+		classifier: classifier,
+		races:      races,
+		collectors: []cgroups.Collector{
+			memory.NewCollector(races, false),
+			memory.NewEventsCollector(races),
+			io.NewCollector(races),
+		},
+		aggregator: newAggregator(),
+	}
+
+	expected := make(map[string]struct{})
+	for _, service := range services {
+		expected[service] = struct{}{}
+	}
+
+	for range 2 {
+		metrics := make(chan prometheus.Metric, 1000)
+		collector.Collect(metrics)
+		close(metrics)
+
+		collected := make(map[string]struct{})
+		for metric := range metrics {
+			var result dto.Metric
+			require.NoError(t, metric.Write(&result))
+
+			for _, label := range result.GetLabel() {
+				if label.GetName() == "service" {
+					collected[label.GetValue()] = struct{}{}
+				}
+			}
+		}
+
+		require.Equal(t, expected, collected)
+		require.Empty(t, logs.FilterLevelExact(zap.ErrorLevel).All())
+		require.Empty(t, logs.FilterLevelExact(zap.WarnLevel).All())
+		require.Empty(t, logs.FilterMessageSnippet("Suppressing").All())
+	}
+}
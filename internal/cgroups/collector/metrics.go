@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var containerInfoMetric = cgroups.MaxAggregated(metrics.MakeDescBuilder("services").WithLabels("service").Build(
+	"container_info", "Containers the service consists of.", []string{"runtime", "id", "image", "tag", "systemd_unit"}))
@@ -6,28 +6,46 @@ import (
 
 	logging "github.com/KonishchevDmitry/go-easy-logging"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tklauser/go-sysconf"
 
 	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
 	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
 )
 
 type Collector struct {
-	roots map[string]*rootState
-	races *cgroups.RaceController
+	clockFrequency int64
+	roots          map[string]*rootState
+	races          *cgroups.RaceController
 }
 
 var _ cgroups.Collector = &Collector{}
 
-func NewCollector(races *cgroups.RaceController) *Collector {
-	return &Collector{
-		roots: make(map[string]*rootState),
-		races: races,
+func NewCollector(races *cgroups.RaceController) (*Collector, error) {
+	clockFrequency, err := sysconf.Sysconf(sysconf.SC_CLK_TCK)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get SC_CLK_TCK value: %w", err)
 	}
+
+	return &Collector{
+		clockFrequency: clockFrequency,
+		roots:          make(map[string]*rootState),
+		races:          races,
+	}, nil
 }
 
 func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
 	descs <- userMetric
 	descs <- systemMetric
+
+	descs <- periodsMetric
+	descs <- throttledPeriodsMetric
+	descs <- throttledTimeMetric
+	descs <- burstsMetric
+	descs <- burstTimeMetric
+
+	descs <- quotaMetric
+	descs <- periodMetric
+	descs <- weightMetric
 }
 
 func (c *Collector) Pre() {
@@ -72,19 +90,60 @@ func (c *Collector) Collect(
 		}
 	}
 
-	usage, exists, err := c.collect(group)
+	collect := c.collect
+
+	legacy, isLegacy := group.Legacy("cpuacct").Get()
+	if isLegacy {
+		// Legacy hierarchy has no group if CPU accounting isn't enabled for the service. Its usage is accounted to
+		// the parent group in this case.
+		if exists, err := legacy.IsExist(); err != nil {
+			return false, err
+		} else if !exists {
+			logging.L(ctx).Debugf("* %s: cpu: CPU accounting is disabled.", service)
+			return true, nil
+		}
+
+		group = legacy
+		for index, child := range children {
+			children[index] = child.Legacy("cpuacct").MustGet()
+		}
+
+		collect = c.collectLegacy
+	}
+
+	usage, exists, err := collect(group)
 	if err != nil || !exists {
 		return exists, err
 	}
 
 	if isRoot {
-		usage, exists, err = c.collectRoot(group, usage, children)
+		usage, exists, err = c.collectRoot(group, usage, children, collect)
 		if err != nil || !exists {
 			return exists, err
 		}
 	}
 
 	c.record(ctx, service, usage, metrics)
+
+	// Root cgroup has no CPU controller configuration and throttling statistics. Legacy ones aren't supported.
+	if !group.IsRoot() && !isLegacy {
+		// CPU controller may be not enabled for the group
+		enabled, err := group.HasProperty("cpu.max")
+		if err != nil {
+			return false, err
+		} else if !enabled {
+			return true, nil
+		}
+
+		c.recordThrottling(ctx, service, usage, metrics)
+
+		config, exists, err := c.collectConfig(group)
+		if err != nil || !exists {
+			return exists, err
+		}
+		c.recordConfig(ctx, service, config, metrics)
+	}
+
 	return true, nil
 }
 
@@ -103,18 +162,50 @@ func (c *Collector) collect(group *cgroups.Group) (Usage, bool, error) {
 		return Usage{}, true, err
 	}
 
+	// Throttling statistics are available only when CPU controller is enabled for the group (and never for the root
+	// group), bursts statistics – since Linux 5.14.
+	for _, counter := range []struct {
+		name  string
+		value *int64
+	}{
+		{"nr_periods", &usage.periods},
+		{"nr_throttled", &usage.throttledPeriods},
+		{"throttled_usec", &usage.throttled},
+		{"nr_bursts", &usage.bursts},
+		{"burst_usec", &usage.burst},
+	} {
+		*counter.value, _ = stats.Lookup(counter.name)
+	}
+	_, usage.hasBursts = stats.Lookup("nr_bursts")
+
 	return usage, true, nil
 }
 
-func (c *Collector) collectRoot(group *cgroups.Group, usage Usage, children []*cgroups.Group) (Usage, bool, error) {
+func (c *Collector) collectRoot(
+	group *cgroups.Group, usage Usage, children []*cgroups.Group, collect func(group *cgroups.Group) (Usage, bool, error),
+) (Usage, bool, error) {
 	current := rootUsage{root: usage}
+	_, legacy := group.Legacy("cpuacct").Get()
 
 	for _, child := range children {
-		childUsage, childExists, err := c.collect(child)
+		// CPU accounting may be disabled for the child, so check its existence explicitly to not report the missing
+		// group to the race controller
+		if legacy {
+			if exists, err := child.IsExist(); err != nil {
+				return Usage{}, false, err
+			} else if !exists {
+				continue
+			}
+		}
+
+		childUsage, childExists, err := collect(child)
 		if err != nil {
 			return Usage{}, false, err
 		} else if !childExists {
-			if group.IsRoot() {
+			if legacy {
+				// The child has been deleted during metrics collection
+				continue
+			} else if group.IsRoot() {
 				return Usage{}, false, fmt.Errorf("%q has been deleted during metrics collection", child.Path())
 			} else {
 				return Usage{}, false, c.races.Check(group, fmt.Errorf(
@@ -137,7 +228,10 @@ func (c *Collector) collectRoot(group *cgroups.Group, usage Usage, children []*c
 	state.lastUsage = current
 	state.collected = true
 
-	return state.netUsage, true, nil
+	usage = state.netUsage
+	usage.hasBursts = current.root.hasBursts
+
+	return usage, true, nil
 }
 
 func (c *Collector) record(ctx context.Context, service string, usage Usage, metrics chan<- prometheus.Metric) {
@@ -145,6 +239,7 @@ func (c *Collector) record(ctx context.Context, service string, usage Usage, met
 
 	user := float64(usage.user) / usec
 	system := float64(usage.system) / usec
+
 	logging.L(ctx).Debugf("* %s: cpu: user=%.1fs, system=%.1fs", service, user, system)
 
 	metrics <- prometheus.MustNewConstMetric(userMetric, prometheus.CounterValue, user, service)
@@ -154,6 +249,13 @@ func (c *Collector) record(ctx context.Context, service string, usage Usage, met
 type Usage struct {
 	user   int64
 	system int64
+
+	periods          int64
+	throttledPeriods int64
+	throttled        int64
+	bursts           int64
+	burst            int64
+	hasBursts        bool
 }
 
 var _ cgroups.ToUsage = &Usage{}
@@ -162,6 +264,12 @@ func (u *Usage) ToUsage() []cgroups.Usage {
 	return []cgroups.Usage{
 		cgroups.MakeUsage("user CPU usage", &u.user),
 		cgroups.MakeUsage("system CPU usage", &u.system),
+
+		cgroups.MakeUsage("CPU enforcement periods", &u.periods),
+		cgroups.MakeUsage("CPU throttled periods", &u.throttledPeriods),
+		cgroups.MakeUsage("CPU throttled time", &u.throttled),
+		cgroups.MakeUsage("CPU bursts", &u.bursts),
+		cgroups.MakeUsage("CPU burst time", &u.burst),
 	}
 }
 
@@ -0,0 +1,88 @@
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/mo"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+)
+
+type Config struct {
+	quota  mo.Option[int64] // None means no limit
+	period int64
+	weight int64
+}
+
+// Must be called only for the groups with CPU controller enabled
+func (c *Collector) collectConfig(group *cgroups.Group) (Config, bool, error) {
+	var config Config
+
+	if exists, err := group.ReadProperty("cpu.max", func(file io.Reader) error {
+		data, err := io.ReadAll(file)
+		if err == nil {
+			config.quota, config.period, err = parseMax(string(data))
+		}
+		return err
+	}); err != nil || !exists {
+		return Config{}, exists, err
+	}
+
+	weight, exists, err := cgroupsutil.ReadValue(group, "cpu.weight")
+	if err != nil || !exists {
+		return Config{}, exists, err
+	}
+	config.weight = weight
+
+	return config, true, nil
+}
+
+// cpu.max has "$MAX $PERIOD" format where $MAX may be "max"
+func parseMax(data string) (mo.Option[int64], int64, error) {
+	var quota mo.Option[int64]
+
+	data = strings.TrimSpace(data)
+	tokens := strings.Split(data, " ")
+	if len(tokens) != 2 {
+		return quota, 0, fmt.Errorf("Got an unexpected value: %q", data)
+	}
+
+	if tokens[0] != "max" {
+		value, err := strconv.ParseInt(tokens[0], 10, 64)
+		if err != nil {
+			return quota, 0, fmt.Errorf("Got an unexpected value: %q", data)
+		}
+		quota = mo.Some(value)
+	}
+
+	period, err := strconv.ParseInt(tokens[1], 10, 64)
+	if err != nil {
+		return quota, 0, fmt.Errorf("Got an unexpected value: %q", data)
+	}
+
+	return quota, period, nil
+}
+
+func (c *Collector) recordConfig(ctx context.Context, service string, config Config, metrics chan<- prometheus.Metric) {
+	const usec = 1_000_000
+
+	period := float64(config.period) / usec
+
+	if quota, ok := config.quota.Get(); ok {
+		logging.L(ctx).Debugf("* %s: cpu: quota=%.3fs, period=%.3fs, weight=%d",
+			service, float64(quota)/usec, period, config.weight)
+		metrics <- prometheus.MustNewConstMetric(quotaMetric, prometheus.GaugeValue, float64(quota)/usec, service)
+	} else {
+		logging.L(ctx).Debugf("* %s: cpu: quota=max, period=%.3fs, weight=%d", service, period, config.weight)
+	}
+
+	metrics <- prometheus.MustNewConstMetric(periodMetric, prometheus.GaugeValue, period, service)
+	metrics <- prometheus.MustNewConstMetric(weightMetric, prometheus.GaugeValue, float64(config.weight), service)
+}
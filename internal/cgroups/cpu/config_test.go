@@ -0,0 +1,23 @@
+package cpu
+
+import (
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMax(t *testing.T) {
+	quota, period, err := parseMax("max 100000\n")
+	require.NoError(t, err)
+	require.Equal(t, mo.None[int64](), quota)
+	require.Equal(t, int64(100000), period)
+
+	quota, period, err = parseMax("50000 100000\n")
+	require.NoError(t, err)
+	require.Equal(t, mo.Some[int64](50000), quota)
+	require.Equal(t, int64(100000), period)
+
+	_, _, err = parseMax("50000\n")
+	require.Error(t, err)
+}
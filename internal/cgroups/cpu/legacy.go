@@ -0,0 +1,33 @@
+package cpu
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+)
+
+// Collects CPU usage from cgroup v1 cpuacct controller. Throttling statistics are provided by a separate cpu
+// controller hierarchy, so they aren't collected in this mode.
+func (c *Collector) collectLegacy(group *cgroups.Group) (Usage, bool, error) {
+	stats, exists, err := cgroupsutil.ReadStat(group, "cpuacct.stat")
+	if err != nil || !exists {
+		return Usage{}, exists, err
+	}
+
+	// The values are in USER_HZ units
+	user, err := stats.Get("user")
+	if err != nil {
+		return Usage{}, true, err
+	}
+
+	system, err := stats.Get("system")
+	if err != nil {
+		return Usage{}, true, err
+	}
+
+	const usec = 1_000_000
+
+	return Usage{
+		user:   user * usec / c.clockFrequency,
+		system: system * usec / c.clockFrequency,
+	}, true, nil
+}
@@ -1,10 +1,21 @@
 package cpu
 
 import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
 	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
 )
 
 var metricBuilder = metrics.MakeDescBuilder("services_cpu").WithLabels("service")
 
-var userMetric = metricBuilder.Build("user", "CPU time consumed in user mode.", nil)
-var systemMetric = metricBuilder.Build("system", "CPU time consumed in system (kernel) mode.", nil)
+var userMetric = cgroups.SumAggregated(metricBuilder.Build("user", "CPU time consumed in user mode.", nil))
+var systemMetric = cgroups.SumAggregated(metricBuilder.Build("system", "CPU time consumed in system (kernel) mode.", nil))
+
+var periodsMetric = cgroups.SumAggregated(metricBuilder.Build("periods", "Number of elapsed CPU bandwidth enforcement periods.", nil))
+var throttledPeriodsMetric = cgroups.SumAggregated(metricBuilder.Build("throttled_periods", "Number of enforcement periods in which the service has been throttled.", nil))
+var throttledTimeMetric = cgroups.SumAggregated(metricBuilder.Build("throttled", "Total time the service has been throttled for.", nil))
+var burstsMetric = cgroups.SumAggregated(metricBuilder.Build("bursts", "Number of enforcement periods in which the service has used its CPU burst.", nil))
+var burstTimeMetric = cgroups.SumAggregated(metricBuilder.Build("burst", "CPU time consumed in bursts above the quota.", nil))
+
+var quotaMetric = metricBuilder.Build("quota", "CPU time the service may consume during one enforcement period (unlimited one isn't reported).", nil)
+var periodMetric = metricBuilder.Build("period", "CPU bandwidth enforcement period.", nil)
+var weightMetric = metricBuilder.Build("weight", "CPU weight of the service.", nil)
@@ -0,0 +1,29 @@
+package cpu
+
+import (
+	"context"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Throttling statistics are collected along with CPU usage, so for the root and "total excluding" groups they are
+// calculated as the group's counters minus the counters of its children. They are reported only for the groups with
+// CPU controller enabled.
+func (c *Collector) recordThrottling(ctx context.Context, service string, usage Usage, metrics chan<- prometheus.Metric) {
+	const usec = 1_000_000
+
+	throttled := float64(usage.throttled) / usec
+
+	logging.L(ctx).Debugf("* %s: cpu: periods=%d, throttled=%d (%.1fs)",
+		service, usage.periods, usage.throttledPeriods, throttled)
+
+	metrics <- prometheus.MustNewConstMetric(periodsMetric, prometheus.CounterValue, float64(usage.periods), service)
+	metrics <- prometheus.MustNewConstMetric(throttledPeriodsMetric, prometheus.CounterValue, float64(usage.throttledPeriods), service)
+	metrics <- prometheus.MustNewConstMetric(throttledTimeMetric, prometheus.CounterValue, throttled, service)
+
+	if usage.hasBursts {
+		metrics <- prometheus.MustNewConstMetric(burstsMetric, prometheus.CounterValue, float64(usage.bursts), service)
+		metrics <- prometheus.MustNewConstMetric(burstTimeMetric, prometheus.CounterValue, float64(usage.burst)/usec, service)
+	}
+}
@@ -0,0 +1,122 @@
+package fds
+
+import (
+	"context"
+	"strings"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/mo"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+	"github.com/KonishchevDmitry/server-metrics/internal/procfs"
+)
+
+type Collector struct {
+}
+
+var _ cgroups.Collector = &Collector{}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- openMetric
+	descs <- maxUtilizationMetric
+	descs <- maxUtilizationProcessMetric
+}
+
+func (c *Collector) Pre() {
+}
+
+func (c *Collector) Post(ctx context.Context) {
+}
+
+type processUsage struct {
+	name        string
+	utilization float64
+}
+
+func (c *Collector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	pids, exists, err := group.AllPIDs(exclude)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	var open int
+	var maxUsage mo.Option[processUsage]
+
+	taskErrors := cgroupsutil.NewTaskErrors("fds")
+
+	for _, pid := range pids {
+		usage, fds, exists, err := getProcessUsage(pid)
+		if err != nil {
+			taskErrors.Failed(ctx, pid, err)
+			continue
+		} else if !exists {
+			continue
+		}
+
+		taskErrors.Read()
+		open += fds
+
+		if usage, ok := usage.Get(); ok {
+			if current, ok := maxUsage.Get(); !ok || usage.utilization > current.utilization {
+				maxUsage = mo.Some(usage)
+			}
+		}
+	}
+
+	if err := taskErrors.Err(); err != nil {
+		return true, err
+	}
+
+	logging.L(ctx).Debugf("* %s: fds: %d", service, open)
+	metrics <- prometheus.MustNewConstMetric(openMetric, prometheus.GaugeValue, float64(open), service)
+
+	if usage, ok := maxUsage.Get(); ok {
+		logging.L(ctx).Debugf("* %s: fds max utilization: %.2f (%s)", service, usage.utilization, usage.name)
+		metrics <- prometheus.MustNewConstMetric(maxUtilizationMetric, prometheus.GaugeValue, usage.utilization, service)
+		metrics <- prometheus.MustNewConstMetric(maxUtilizationProcessMetric, prometheus.GaugeValue, 1, service, usage.name)
+	}
+
+	return true, nil
+}
+
+// Returns number of open file descriptors and their utilization (if the process has a limit)
+func getProcessUsage(pid int) (mo.Option[processUsage], int, bool, error) {
+	var usage mo.Option[processUsage]
+
+	fds, exists, err := procfs.ReadDir(pid, "fd")
+	if err != nil || !exists {
+		return usage, 0, exists, err
+	}
+
+	data, exists, err := procfs.ReadFile(pid, "limits")
+	if err != nil || !exists {
+		return usage, 0, exists, err
+	}
+
+	limit, err := parseOpenFilesLimit(data)
+	if err != nil {
+		return usage, 0, false, err
+	}
+
+	if limit, ok := limit.Get(); ok && limit != 0 {
+		name, exists, err := procfs.ReadFile(pid, "comm")
+		if err != nil || !exists {
+			return usage, 0, exists, err
+		}
+
+		usage = mo.Some(processUsage{
+			name:        strings.TrimSuffix(string(name), "\n"),
+			utilization: float64(len(fds)) / float64(limit),
+		})
+	}
+
+	return usage, len(fds), true, nil
+}
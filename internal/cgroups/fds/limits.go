@@ -0,0 +1,52 @@
+package fds
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/samber/mo"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+// Parses soft RLIMIT_NOFILE value from /proc/$pid/limits. Returns None for unlimited limit.
+//
+// https://man7.org/linux/man-pages/man5/proc_pid_limits.5.html
+func parseOpenFilesLimit(data []byte) (mo.Option[uint64], error) {
+	const prefix = "Max open files "
+
+	var limit mo.Option[uint64]
+	found := false
+
+	if err := util.ParseFile(bytes.NewReader(data), func(line string) error {
+		if !strings.HasPrefix(line, prefix) {
+			return nil
+		}
+
+		fields := strings.Fields(line[len(prefix):])
+		if len(fields) != 3 || found {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		if fields[0] != "unlimited" {
+			value, err := strconv.ParseUint(fields[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("Got an unexpected line: %q", line)
+			}
+			limit = mo.Some(value)
+		}
+
+		found = true
+		return nil
+	}); err != nil {
+		return mo.None[uint64](), err
+	}
+
+	if !found {
+		return mo.None[uint64](), fmt.Errorf("Open files limit is missing")
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,28 @@
+package fds
+
+import (
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOpenFilesLimit(t *testing.T) {
+	data := "" +
+		"Limit                     Soft Limit           Hard Limit           Units     \n" +
+		"Max cpu time              unlimited            unlimited            seconds   \n" +
+		"Max processes             62570                62570                processes \n" +
+		"Max open files            1024                 524288               files     \n" +
+		"Max locked memory         8388608              8388608              bytes     \n"
+
+	limit, err := parseOpenFilesLimit([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, mo.Some[uint64](1024), limit)
+
+	limit, err = parseOpenFilesLimit([]byte("Max open files            unlimited            unlimited            files     \n"))
+	require.NoError(t, err)
+	require.Equal(t, mo.None[uint64](), limit)
+
+	_, err = parseOpenFilesLimit([]byte("Max cpu time              unlimited            unlimited            seconds   \n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,16 @@
+package fds
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var metricBuilder = metrics.MakeDescBuilder("services_fds").WithLabels("service")
+
+var openMetric = cgroups.SumAggregated(metricBuilder.Build("open", "Number of file descriptors opened by the service processes.", nil))
+
+var maxUtilizationMetric = cgroups.MaxAggregated(metricBuilder.Build(
+	"max_utilization", "The highest ratio of open file descriptors to RLIMIT_NOFILE among the service processes.", nil))
+
+var maxUtilizationProcessMetric = cgroups.MaxAggregated(metricBuilder.Build(
+	"max_utilization_process_info", "The service process with the highest open file descriptors utilization.", []string{"process"}))
@@ -0,0 +1,122 @@
+package fileio
+
+import (
+	"context"
+	"fmt"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+	"github.com/KonishchevDmitry/server-metrics/internal/procfs"
+)
+
+// Collector collects file-level I/O of the service processes. Unlike io.Collector it accounts all reads and writes
+// including page cache, tmpfs, sockets and pipes ones.
+//
+// /proc/$pid/io counters include the counters of the process's reaped children, so the collector tracks the process
+// tree to not account the children's I/O twice.
+type Collector struct {
+	counters *cgroupsutil.TaskCounters
+}
+
+var _ cgroups.Collector = &Collector{}
+
+func NewCollector() *Collector {
+	return &Collector{
+		counters: cgroupsutil.NewTaskCounters("fileio"),
+	}
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- readsMetric
+	descs <- writesMetric
+
+	descs <- readBytesMetric
+	descs <- writtenBytesMetric
+	descs <- cancelledWrittenBytesMetric
+}
+
+func (c *Collector) Pre() {
+	c.counters.Pre()
+}
+
+func (c *Collector) Post(ctx context.Context) {
+	c.counters.Post(ctx)
+}
+
+func (c *Collector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	pids, exists, err := group.AllPIDs(exclude)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	processes := make(map[int][]uint64, len(pids))
+	parents := make(map[int]int, len(pids))
+
+	taskErrors := cgroupsutil.NewTaskErrors("fileio")
+
+	for _, pid := range pids {
+		counters, parent, exists, err := readProcess(pid)
+		if err != nil {
+			taskErrors.Failed(ctx, pid, err)
+			continue
+		} else if !exists {
+			continue
+		}
+
+		taskErrors.Read()
+		processes[pid] = counters
+		parents[pid] = parent
+	}
+
+	if err := taskErrors.Err(); err != nil {
+		return true, err
+	}
+
+	usage := fromCounters(c.counters.UpdateProcesses(group.Name, processes, parents))
+	c.record(ctx, service, usage, metrics)
+
+	return true, nil
+}
+
+// Returns the process I/O counters and its parent PID
+func readProcess(pid int) ([]uint64, int, bool, error) {
+	data, exists, err := procfs.ReadFile(pid, "stat")
+	if err != nil || !exists {
+		return nil, 0, exists, err
+	}
+
+	procStat, ok := procfs.ParseStat(data)
+	if !ok {
+		return nil, 0, false, fmt.Errorf("/proc/%d/stat has an unexpected data: %q", pid, string(data))
+	}
+
+	data, exists, err = procfs.ReadFile(pid, "io")
+	if err != nil || !exists {
+		return nil, 0, exists, err
+	}
+
+	stat, err := parseIO(data)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	return stat.toCounters(), procStat.ParentPID, true, nil
+}
+
+func (c *Collector) record(ctx context.Context, service string, usage ioStat, metrics chan<- prometheus.Metric) {
+	logging.L(ctx).Debugf(
+		"* %s: fileio: reads=%d, writes=%d, read=%d, written=%d, cancelled=%d",
+		service, usage.reads, usage.writes, usage.read, usage.written, usage.cancelledWritten)
+
+	metrics <- prometheus.MustNewConstMetric(readsMetric, prometheus.CounterValue, float64(usage.reads), service)
+	metrics <- prometheus.MustNewConstMetric(writesMetric, prometheus.CounterValue, float64(usage.writes), service)
+
+	metrics <- prometheus.MustNewConstMetric(readBytesMetric, prometheus.CounterValue, float64(usage.read), service)
+	metrics <- prometheus.MustNewConstMetric(writtenBytesMetric, prometheus.CounterValue, float64(usage.written), service)
+	metrics <- prometheus.MustNewConstMetric(cancelledWrittenBytesMetric, prometheus.CounterValue, float64(usage.cancelledWritten), service)
+}
@@ -0,0 +1,15 @@
+package fileio
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var metricBuilder = metrics.MakeDescBuilder("services_fileio").WithLabels("service")
+
+var readsMetric = cgroups.SumAggregated(metricBuilder.Build("reads", "Number of read syscalls issued by the service.", nil))
+var writesMetric = cgroups.SumAggregated(metricBuilder.Build("writes", "Number of write syscalls issued by the service.", nil))
+
+var readBytesMetric = cgroups.SumAggregated(metricBuilder.Build("read_bytes", "Number of bytes read by the service from files, sockets, pipes, etc.", nil))
+var writtenBytesMetric = cgroups.SumAggregated(metricBuilder.Build("written_bytes", "Number of bytes written by the service to files, sockets, pipes, etc.", nil))
+var cancelledWrittenBytesMetric = cgroups.SumAggregated(metricBuilder.Build("cancelled_written_bytes", "Number of bytes which the service caused to not be written to the disk by truncating dirty page cache.", nil))
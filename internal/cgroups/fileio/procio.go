@@ -0,0 +1,80 @@
+package fileio
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+type ioStat struct {
+	reads  uint64
+	writes uint64
+
+	read             uint64
+	written          uint64
+	cancelledWritten uint64
+}
+
+func (s *ioStat) toCounters() []uint64 {
+	return []uint64{s.reads, s.writes, s.read, s.written, s.cancelledWritten}
+}
+
+func fromCounters(counters []uint64) ioStat {
+	if counters == nil {
+		return ioStat{}
+	}
+	return ioStat{
+		reads:            counters[0],
+		writes:           counters[1],
+		read:             counters[2],
+		written:          counters[3],
+		cancelledWritten: counters[4],
+	}
+}
+
+// https://man7.org/linux/man-pages/man5/proc_pid_io.5.html
+func parseIO(data []byte) (ioStat, error) {
+	var stat ioStat
+
+	fields := map[string]*uint64{
+		"syscr":                 &stat.reads,
+		"syscw":                 &stat.writes,
+		"rchar":                 &stat.read,
+		"wchar":                 &stat.written,
+		"cancelled_write_bytes": &stat.cancelledWritten,
+	}
+	found := 0
+
+	if err := util.ParseFile(bytes.NewReader(data), func(line string) error {
+		name, valueString, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			return nil
+		}
+
+		value, err := strconv.ParseUint(strings.TrimSpace(valueString), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		*field = value
+		found++
+
+		return nil
+	}); err != nil {
+		return ioStat{}, err
+	}
+
+	if found != len(fields) {
+		return ioStat{}, fmt.Errorf("Some of the fields are missing: %q", string(data))
+	}
+
+	return stat, nil
+}
@@ -0,0 +1,31 @@
+package fileio
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIO(t *testing.T) {
+	stat, err := parseIO([]byte(heredoc.Doc(`
+		rchar: 323934931
+		wchar: 323929600
+		syscr: 632687
+		syscw: 632675
+		read_bytes: 0
+		write_bytes: 323932160
+		cancelled_write_bytes: 4096
+	`)))
+	require.NoError(t, err)
+	require.Equal(t, ioStat{
+		reads:            632687,
+		writes:           632675,
+		read:             323934931,
+		written:          323929600,
+		cancelledWritten: 4096,
+	}, stat)
+
+	_, err = parseIO([]byte("rchar: 323934931\n"))
+	require.Error(t, err)
+}
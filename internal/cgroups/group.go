@@ -6,7 +6,9 @@ import (
 	"io"
 	"os"
 	"path"
+	"slices"
 	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/samber/mo"
@@ -14,16 +16,23 @@ import (
 	"github.com/KonishchevDmitry/server-metrics/internal/util"
 )
 
-const rootPath = "/sys/fs/cgroup"
-
 type Group struct {
 	Name  string
+	root  string
 	races mo.Option[*RaceController]
+
+	// AllPIDs() and AllThreads() results. Group objects are created during each collection, so the cache allows
+	// collectors of the same group to share the lists without rereading them.
+	allPIDs map[string][]int
+
+	// Cached cgroup.controllers contents
+	controllers map[string]struct{}
 }
 
 func NewGroup(name string, races *RaceController) *Group {
 	return &Group{
 		Name:  name,
+		root:  hierarchy.root,
 		races: mo.EmptyableToOption(races),
 	}
 }
@@ -37,11 +46,29 @@ func (g *Group) IsExist() (bool, error) {
 }
 
 func (g *Group) Path() string {
-	return path.Join(rootPath, g.Name)
+	return path.Join(g.root, g.Name)
 }
 
 func (g *Group) Child(name string) *Group {
-	return NewGroup(path.Join(g.Name, name), g.races.OrEmpty())
+	return &Group{
+		Name:  path.Join(g.Name, name),
+		root:  g.root,
+		races: g.races,
+	}
+}
+
+// Legacy returns the same group in the controller's legacy hierarchy if the controller is mounted as cgroup v1.
+func (g *Group) Legacy(controller string) mo.Option[*Group] {
+	root, ok := hierarchy.legacy[controller]
+	if !ok {
+		return mo.None[*Group]()
+	}
+
+	return mo.Some(&Group{
+		Name:  g.Name,
+		root:  root,
+		races: g.races,
+	})
 }
 
 func (g *Group) Children() ([]*Group, bool, error) {
@@ -62,33 +89,62 @@ func (g *Group) Children() ([]*Group, bool, error) {
 }
 
 func (g *Group) PIDs() ([]int, bool, error) {
-	var pids []int
+	return g.readPIDs("cgroup.procs")
+}
 
-	if exists, err := g.ReadProperty("cgroup.procs", func(file io.Reader) error {
-		return util.ParseFile(file, func(line string) error {
-			pid, err := strconv.ParseInt(line, 10, 32)
-			if err != nil || pid <= 0 {
-				return fmt.Errorf("PID is expected, but got %q line", line)
+func (g *Group) Threads() ([]int, bool, error) {
+	return g.readPIDs(threadsProperty())
+}
+
+// AllPIDs returns PIDs of all processes of the group and its descendants except the excluded children. Root group
+// children are never traversed since they are always collected separately.
+//
+// The result is cached in the group object, so the returned slice must not be modified.
+func (g *Group) AllPIDs(exclude []string) ([]int, bool, error) {
+	return g.cachedAllPIDs("cgroup.procs", exclude)
+}
+
+// AllThreads is the same as AllPIDs, but returns thread IDs.
+func (g *Group) AllThreads(exclude []string) ([]int, bool, error) {
+	return g.cachedAllPIDs(threadsProperty(), exclude)
+}
+
+func (g *Group) HasProcesses() (bool, bool, error) {
+	pids, exists, err := g.PIDs()
+	return len(pids) != 0, exists, err
+}
+
+// HasController returns true if the controller is enabled for the group in the unified hierarchy (its interface files
+// are present). Domain controllers like memory and io are never enabled for threaded groups: usage of their threads is
+// accounted to the nearest domain group.
+func (g *Group) HasController(name string) (bool, bool, error) {
+	if g.controllers == nil {
+		controllers := make(map[string]struct{})
+
+		if exists, err := g.ReadProperty("cgroup.controllers", func(file io.Reader) error {
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return err
 			}
-			pids = append(pids, int(pid))
+
+			for _, controller := range strings.Fields(string(data)) {
+				controllers[controller] = struct{}{}
+			}
+
 			return nil
-		})
-	}); err != nil {
-		if errors.Is(err, syscall.EOPNOTSUPP) {
-			// cgroup.type == threaded
-			return nil, true, nil
+		}); err != nil || !exists {
+			return false, exists, err
 		}
-		return nil, false, err
-	} else if !exists {
-		return nil, false, nil
+
+		g.controllers = controllers
 	}
 
-	return pids, true, nil
+	_, ok := g.controllers[name]
+	return ok, true, nil
 }
 
-func (g *Group) HasProcesses() (bool, bool, error) {
-	pids, exists, err := g.PIDs()
-	return len(pids) != 0, exists, err
+func (g *Group) HasProperty(name string) (bool, error) {
+	return isExist(path.Join(g.Path(), name))
 }
 
 func (g *Group) ReadProperty(name string, reader func(file io.Reader) error) (bool, error) {
@@ -108,6 +164,84 @@ func (g *Group) ReadProperty(name string, reader func(file io.Reader) error) (bo
 	}
 }
 
+func (g *Group) cachedAllPIDs(name string, exclude []string) ([]int, bool, error) {
+	key := strings.Join(append([]string{name}, exclude...), "\x00")
+	if pids, ok := g.allPIDs[key]; ok {
+		return pids, true, nil
+	}
+
+	pids, exists, err := g.readAllPIDs(name, exclude)
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	if g.allPIDs == nil {
+		g.allPIDs = make(map[string][]int)
+	}
+	g.allPIDs[key] = pids
+
+	return pids, true, nil
+}
+
+func (g *Group) readAllPIDs(name string, exclude []string) ([]int, bool, error) {
+	pids, exists, err := g.readPIDs(name)
+	if err != nil || !exists || g.IsRoot() {
+		return pids, exists, err
+	}
+
+	children, exists, err := g.Children()
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	for _, child := range children {
+		if slices.Contains(exclude, path.Base(child.Name)) {
+			continue
+		}
+
+		// The child may be deleted during the traversal – it's not an error
+		childPIDs, _, err := child.readAllPIDs(name, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		pids = append(pids, childPIDs...)
+	}
+
+	return pids, true, nil
+}
+
+func (g *Group) readPIDs(name string) ([]int, bool, error) {
+	var pids []int
+
+	if exists, err := g.ReadProperty(name, func(file io.Reader) error {
+		return util.ParseFile(file, func(line string) error {
+			pid, err := strconv.ParseInt(line, 10, 32)
+			if err != nil || pid <= 0 {
+				return fmt.Errorf("PID is expected, but got %q line", line)
+			}
+			pids = append(pids, int(pid))
+			return nil
+		})
+	}); err != nil {
+		if errors.Is(err, syscall.EOPNOTSUPP) {
+			// cgroup.type == threaded
+			return nil, true, nil
+		}
+		return nil, false, err
+	} else if !exists {
+		return nil, false, nil
+	}
+
+	return pids, true, nil
+}
+
+func threadsProperty() string {
+	if hierarchy.version == Legacy {
+		return "tasks"
+	}
+	return "cgroup.threads"
+}
+
 func (g *Group) list() ([]os.DirEntry, bool, error) {
 	files, err := os.ReadDir(g.Path())
 	if err != nil {
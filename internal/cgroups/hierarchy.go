@@ -0,0 +1,252 @@
+package cgroups
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+type Version int
+
+const (
+	// Unified is cgroup v2 hierarchy
+	Unified Version = iota
+	// Hybrid is cgroup v1 hierarchy with cgroup v2 mounted without controllers for process tracking (systemd's
+	// default for a long time)
+	Hybrid
+	// Legacy is cgroup v1 hierarchy
+	Legacy
+)
+
+func (v Version) String() string {
+	switch v {
+	case Unified:
+		return "unified"
+	case Hybrid:
+		return "hybrid"
+	case Legacy:
+		return "legacy"
+	default:
+		return fmt.Sprintf("unknown (%d)", int(v))
+	}
+}
+
+// Hierarchy describes mounted cgroup hierarchies. Groups are always discovered in a single hierarchy which mirrors
+// systemd's units layout: unified one for cgroup v2 and hybrid mode or systemd's named one for legacy mode. Legacy
+// controllers have their own hierarchies, where groups have the same names as in the discovery hierarchy, but may
+// be missing if accounting isn't enabled for them.
+type Hierarchy struct {
+	version     Version
+	root        string
+	controllers map[string]struct{}
+	legacy      map[string]string
+}
+
+// The hierarchy is detected once at startup by Init(). Unified hierarchy is assumed by default.
+var hierarchy = &Hierarchy{
+	version: Unified,
+	root:    "/sys/fs/cgroup",
+}
+
+// Init detects mounted cgroup hierarchy and configures all groups to use it.
+func Init() (*Hierarchy, error) {
+	mountInfo, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+
+	mounts, err := parseMountInfo(mountInfo)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse /proc/self/mountinfo: %w", err)
+	}
+
+	detected, err := detectHierarchy(mounts, readControllers)
+	if err != nil {
+		return nil, err
+	}
+
+	hierarchy = detected
+	return hierarchy, nil
+}
+
+// InitUnified configures all groups to use unified hierarchy mounted at the specified root. It's intended for tests
+// which emulate the hierarchy in a temporary directory.
+func InitUnified(root string) (*Hierarchy, error) {
+	controllers, err := readControllers(root)
+	if err != nil {
+		return nil, err
+	}
+
+	detected := &Hierarchy{
+		version:     Unified,
+		root:        root,
+		controllers: make(map[string]struct{}, len(controllers)),
+	}
+	for _, controller := range controllers {
+		detected.controllers[controller] = struct{}{}
+	}
+
+	hierarchy = detected
+	return hierarchy, nil
+}
+
+// CurrentHierarchy returns the hierarchy detected by Init().
+func CurrentHierarchy() *Hierarchy {
+	return hierarchy
+}
+
+func (h *Hierarchy) Version() Version {
+	return h.version
+}
+
+func (h *Hierarchy) Root() string {
+	return h.root
+}
+
+// HasController returns true if the controller is available in the unified hierarchy.
+func (h *Hierarchy) HasController(name string) bool {
+	if h.controllers == nil {
+		return h.version == Unified
+	}
+	_, ok := h.controllers[name]
+	return ok
+}
+
+type mount struct {
+	point   string
+	fsType  string
+	options []string
+}
+
+// Superblock options of legacy hierarchy mounts which aren't controller names
+var legacyMountOptions = map[string]struct{}{
+	"rw":             {},
+	"ro":             {},
+	"noprefix":       {},
+	"xattr":          {},
+	"clone_children": {},
+	"cpuset_v2_mode": {},
+	"favordynmods":   {},
+}
+
+func detectHierarchy(mounts []mount, readControllers func(root string) ([]string, error)) (*Hierarchy, error) {
+	var unified, named string
+	legacy := make(map[string]string)
+
+	for _, mount := range mounts {
+		switch mount.fsType {
+		case "cgroup2":
+			if unified == "" {
+				unified = mount.point
+			}
+
+		case "cgroup":
+			for _, option := range mount.options {
+				if option == "name=systemd" {
+					if named == "" {
+						named = mount.point
+					}
+				} else if _, ok := legacyMountOptions[option]; !ok && !strings.Contains(option, "=") {
+					if _, ok := legacy[option]; !ok {
+						legacy[option] = mount.point
+					}
+				}
+			}
+		}
+	}
+
+	detected := &Hierarchy{legacy: legacy}
+
+	switch {
+	case unified != "" && len(legacy) == 0:
+		detected.version = Unified
+		detected.root = unified
+	case unified != "":
+		detected.version = Hybrid
+		detected.root = unified
+	case named != "":
+		detected.version = Legacy
+		detected.root = named
+		detected.controllers = make(map[string]struct{})
+		return detected, nil
+	default:
+		return nil, fmt.Errorf("Unable to find a supported cgroup hierarchy")
+	}
+
+	controllers, err := readControllers(unified)
+	if err != nil {
+		return nil, err
+	}
+
+	detected.controllers = make(map[string]struct{}, len(controllers))
+	for _, controller := range controllers {
+		detected.controllers[controller] = struct{}{}
+	}
+
+	return detected, nil
+}
+
+func readControllers(root string) ([]string, error) {
+	data, err := os.ReadFile(path.Join(root, "cgroup.controllers"))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// https://man7.org/linux/man-pages/man5/proc_pid_mountinfo.5.html
+func parseMountInfo(data []byte) ([]mount, error) {
+	var mounts []mount
+
+	err := util.ParseFile(bytes.NewReader(data), func(line string) error {
+		fields := strings.Fields(line)
+
+		separator := -1
+		for index := 6; index < len(fields); index++ {
+			if fields[index] == "-" {
+				separator = index
+				break
+			}
+		}
+		if separator == -1 || len(fields) < separator+4 {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		mounts = append(mounts, mount{
+			point:   unescapeMountPoint(fields[4]),
+			fsType:  fields[separator+1],
+			options: strings.Split(fields[separator+3], ","),
+		})
+
+		return nil
+	})
+
+	return mounts, err
+}
+
+// Mount points have space, tab, newline and backslash characters escaped as octal sequences
+func unescapeMountPoint(point string) string {
+	if !strings.Contains(point, `\`) {
+		return point
+	}
+
+	var result strings.Builder
+
+	for index := 0; index < len(point); index++ {
+		if point[index] == '\\' && index+3 < len(point) {
+			if char, err := strconv.ParseUint(point[index+1:index+4], 8, 8); err == nil {
+				result.WriteByte(byte(char))
+				index += 3
+				continue
+			}
+		}
+		result.WriteByte(point[index])
+	}
+
+	return result.String()
+}
@@ -0,0 +1,74 @@
+package cgroups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMountInfo(t *testing.T) {
+	mounts, err := parseMountInfo([]byte(
+		"24 30 0:22 / /sys rw,nosuid,nodev,noexec,relatime shared:7 - sysfs sysfs rw\n" +
+			"33 24 0:28 / /sys/fs/cgroup ro,nosuid,nodev,noexec shared:9 - tmpfs tmpfs ro,mode=755\n" +
+			"34 33 0:29 / /sys/fs/cgroup/unified rw,nosuid,nodev,noexec,relatime shared:10 - cgroup2 cgroup2 rw,nsdelegate\n" +
+			"35 33 0:30 / /sys/fs/cgroup/systemd rw,nosuid,nodev,noexec,relatime shared:11 - cgroup cgroup rw,xattr,name=systemd\n" +
+			"38 33 0:33 / /sys/fs/cgroup/cpu,cpuacct rw,nosuid,nodev,noexec,relatime shared:15 - cgroup cgroup rw,cpu,cpuacct\n" +
+			"40 30 8:1 / /mnt/with\\040space rw,relatime - ext4 /dev/sda1 rw\n",
+	))
+	require.NoError(t, err)
+	require.Equal(t, []mount{
+		{point: "/sys", fsType: "sysfs", options: []string{"rw"}},
+		{point: "/sys/fs/cgroup", fsType: "tmpfs", options: []string{"ro", "mode=755"}},
+		{point: "/sys/fs/cgroup/unified", fsType: "cgroup2", options: []string{"rw", "nsdelegate"}},
+		{point: "/sys/fs/cgroup/systemd", fsType: "cgroup", options: []string{"rw", "xattr", "name=systemd"}},
+		{point: "/sys/fs/cgroup/cpu,cpuacct", fsType: "cgroup", options: []string{"rw", "cpu", "cpuacct"}},
+		{point: "/mnt/with space", fsType: "ext4", options: []string{"rw"}},
+	}, mounts)
+}
+
+func TestDetectHierarchy(t *testing.T) {
+	readControllers := func(root string) ([]string, error) {
+		return []string{"cpu", "io", "memory", "pids"}, nil
+	}
+	noControllers := func(root string) ([]string, error) {
+		return nil, nil
+	}
+
+	unified := mount{point: "/sys/fs/cgroup", fsType: "cgroup2", options: []string{"rw", "nsdelegate"}}
+
+	hierarchy, err := detectHierarchy([]mount{unified}, readControllers)
+	require.NoError(t, err)
+	require.Equal(t, Unified, hierarchy.Version())
+	require.Equal(t, "/sys/fs/cgroup", hierarchy.Root())
+	require.True(t, hierarchy.HasController("memory"))
+	require.Empty(t, hierarchy.legacy)
+
+	named := mount{point: "/sys/fs/cgroup/systemd", fsType: "cgroup", options: []string{"rw", "xattr", "name=systemd"}}
+	legacy := []mount{
+		named,
+		{point: "/sys/fs/cgroup/cpu,cpuacct", fsType: "cgroup", options: []string{"rw", "cpu", "cpuacct"}},
+		{point: "/sys/fs/cgroup/memory", fsType: "cgroup", options: []string{"rw", "memory"}},
+	}
+
+	hierarchy, err = detectHierarchy(append([]mount{
+		{point: "/sys/fs/cgroup/unified", fsType: "cgroup2", options: []string{"rw", "nsdelegate"}},
+	}, legacy...), noControllers)
+	require.NoError(t, err)
+	require.Equal(t, Hybrid, hierarchy.Version())
+	require.Equal(t, "/sys/fs/cgroup/unified", hierarchy.Root())
+	require.False(t, hierarchy.HasController("memory"))
+	require.Equal(t, map[string]string{
+		"cpu":     "/sys/fs/cgroup/cpu,cpuacct",
+		"cpuacct": "/sys/fs/cgroup/cpu,cpuacct",
+		"memory":  "/sys/fs/cgroup/memory",
+	}, hierarchy.legacy)
+
+	hierarchy, err = detectHierarchy(legacy, readControllers)
+	require.NoError(t, err)
+	require.Equal(t, Legacy, hierarchy.Version())
+	require.Equal(t, "/sys/fs/cgroup/systemd", hierarchy.Root())
+	require.False(t, hierarchy.HasController("memory"))
+
+	_, err = detectHierarchy(legacy[1:], readControllers)
+	require.Error(t, err)
+}
@@ -34,6 +34,25 @@ func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
 
 	descs <- readBytesMetric
 	descs <- writtenBytesMetric
+
+	descs <- discardsMetric
+	descs <- discardedBytesMetric
+
+	descs <- costUsageMetric
+	descs <- costWaitMetric
+	descs <- costIndebtMetric
+	descs <- costIndelayMetric
+	descs <- costVrateMetric
+
+	descs <- latencyDepthMetric
+	descs <- latencyAverageMetric
+	descs <- latencyWindowMetric
+
+	descs <- limitMetric
+	descs <- weightMetric
+
+	descs <- costModelMetric
+	descs <- costQoSMetric
 }
 
 func (c *Collector) Pre() {
@@ -79,19 +98,72 @@ func (c *Collector) Collect(
 		}
 	}
 
-	usage, exists, err := c.collect(group)
+	collect := c.collect
+
+	legacy, isLegacy := group.Legacy("blkio").Get()
+	if isLegacy {
+		// Legacy hierarchy has no group if I/O accounting isn't enabled for the service. Its usage is accounted to
+		// the parent group in this case.
+		if exists, err := legacy.IsExist(); err != nil {
+			return false, err
+		} else if !exists {
+			logging.L(ctx).Debugf("* %s: io: I/O accounting is disabled.", service)
+			return true, nil
+		}
+
+		group = legacy
+		for index, child := range children {
+			children[index] = child.Legacy("blkio").MustGet()
+		}
+
+		collect = c.collectLegacy
+	} else if !group.IsRoot() {
+		// Threaded groups (libvirt's vcpu threads for example) have no domain controllers. Their usage is accounted to
+		// the nearest domain group.
+		if enabled, exists, err := group.HasController("io"); err != nil || !exists {
+			return exists, err
+		} else if !enabled {
+			logging.L(ctx).Debugf("* %s: io: I/O controller is disabled.", service)
+			return true, nil
+		}
+	}
+
+	usage, exists, err := collect(group)
 	if err != nil || !exists {
 		return exists, err
 	}
 
+	// QoS statistics are gauges, so they aren't subject to root usage calculation
+	qos := usage.qos()
+
 	if isRoot {
-		usage, exists, err = c.collectRoot(group, usage, children)
+		usage, exists, err = c.collectRoot(group, usage, children, collect)
+		if err != nil || !exists {
+			return exists, err
+		}
+	}
+
+	c.record(ctx, service, usage, qos, metrics)
+
+	// Legacy I/O controller configuration isn't supported
+	if isLegacy {
+		return true, nil
+	}
+
+	// Root cgroup has no I/O controller configuration, but has device-level iocost configuration instead
+	if group.IsRoot() {
+		// The configuration isn't related to the root group usage, so don't fail the remaining collectors on error
+		if err := c.collectCostConfig(ctx, group, metrics); err != nil {
+			logging.L(ctx).Errorf("Failed to collect blk-iocost configuration: %s.", err)
+		}
+	} else {
+		config, exists, err := c.collectConfig(group)
 		if err != nil || !exists {
 			return exists, err
 		}
+		c.recordConfig(ctx, service, config, metrics)
 	}
 
-	c.record(ctx, service, usage, metrics)
 	return true, nil
 }
 
@@ -128,25 +200,54 @@ func (c *Collector) collect(group *cgroups.Group) (Usage, bool, error) {
 		if keyErr != nil {
 			return Usage{}, true, keyErr
 		}
+
+		// Discard statistics appeared in Linux 5.0
+		usage[device].discards, _ = stat.Lookup("dios")
+		usage[device].discarded, _ = stat.Lookup("dbytes")
+
+		usage[device].parseQoS(&stat)
 	}
 
 	return usage, true, nil
 }
 
-func (c *Collector) collectRoot(group *cgroups.Group, totalUsage Usage, children []*cgroups.Group) (Usage, bool, error) {
+func (c *Collector) collectRoot(
+	group *cgroups.Group, totalUsage Usage, children []*cgroups.Group, collect func(group *cgroups.Group) (Usage, bool, error),
+) (Usage, bool, error) {
 	isRoot := group.IsRoot()
+	_, legacy := group.Legacy("blkio").Get()
 
 	currentUsage := make(map[string]*rootUsage, len(totalUsage))
 	for device, usage := range totalUsage {
 		currentUsage[device] = &rootUsage{root: *usage}
 	}
 
+	var collectedChildren []*cgroups.Group
+
 	for _, child := range children {
-		childUsage, childExists, err := c.collect(child)
+		// I/O accounting may be disabled for the child, so check its existence explicitly to not report the missing
+		// group to the race controller
+		if legacy {
+			if exists, err := child.IsExist(); err != nil {
+				return Usage{}, false, err
+			} else if !exists {
+				continue
+			}
+		} else if enabled, exists, err := child.HasController("io"); err != nil {
+			return Usage{}, false, err
+		} else if exists && !enabled {
+			// Threaded child's usage is accounted to the group itself
+			continue
+		}
+
+		childUsage, childExists, err := collect(child)
 		if err != nil {
 			return Usage{}, false, err
 		} else if !childExists {
-			if isRoot {
+			if legacy {
+				// The child has been deleted during metrics collection
+				continue
+			} else if isRoot {
 				return Usage{}, false, fmt.Errorf("%q has been deleted during metrics collection", child.Path())
 			} else {
 				return Usage{}, false, c.races.Check(group, fmt.Errorf(
@@ -162,12 +263,14 @@ func (c *Collector) collectRoot(group *cgroups.Group, totalUsage Usage, children
 			}
 			cgroups.AddUsage(&total.children, usage)
 		}
+
+		collectedChildren = append(collectedChildren, child)
 	}
 
 	var currentChildren map[string]struct{}
 	if isRoot {
-		currentChildren = make(map[string]struct{}, len(children))
-		for _, child := range children {
+		currentChildren = make(map[string]struct{}, len(collectedChildren))
+		for _, child := range collectedChildren {
 			currentChildren[child.Name] = struct{}{}
 		}
 	}
@@ -216,30 +319,46 @@ func (c *Collector) collectRoot(group *cgroups.Group, totalUsage Usage, children
 	return state.netUsage, true, nil
 }
 
-func (c *Collector) record(ctx context.Context, service string, usage Usage, metrics chan<- prometheus.Metric) {
-	for device, stat := range usage {
-		device = c.resolver.getDeviceName(ctx, device)
+func (c *Collector) record(
+	ctx context.Context, service string, usage Usage, qos map[string]deviceQoS, metrics chan<- prometheus.Metric,
+) {
+	for deviceID, stat := range usage {
+		device := c.resolver.getDeviceName(ctx, deviceID)
 
 		logging.L(ctx).Debugf(
-			"* %s: %s: reads=%d, writes=%d, read=%d, written=%d",
-			service, device, stat.reads, stat.writes, stat.read, stat.written)
+			"* %s: %s: reads=%d, writes=%d, discards=%d, read=%d, written=%d, discarded=%d",
+			service, device, stat.reads, stat.writes, stat.discards, stat.read, stat.written, stat.discarded)
 
 		metrics <- prometheus.MustNewConstMetric(readsMetric, prometheus.CounterValue, float64(stat.reads), service, device)
 		metrics <- prometheus.MustNewConstMetric(writesMetric, prometheus.CounterValue, float64(stat.writes), service, device)
 
 		metrics <- prometheus.MustNewConstMetric(readBytesMetric, prometheus.CounterValue, float64(stat.read), service, device)
 		metrics <- prometheus.MustNewConstMetric(writtenBytesMetric, prometheus.CounterValue, float64(stat.written), service, device)
+
+		metrics <- prometheus.MustNewConstMetric(discardsMetric, prometheus.CounterValue, float64(stat.discards), service, device)
+		metrics <- prometheus.MustNewConstMetric(discardedBytesMetric, prometheus.CounterValue, float64(stat.discarded), service, device)
+
+		c.recordQoS(ctx, service, device, stat, qos[deviceID], metrics)
 	}
 }
 
 type Usage map[string]*deviceUsage
 
 type deviceUsage struct {
-	reads  int64
-	writes int64
+	reads    int64
+	writes   int64
+	discards int64
+
+	read      int64
+	written   int64
+	discarded int64
 
-	read    int64
-	written int64
+	costUsage   int64
+	costWait    int64
+	costIndebt  int64
+	costIndelay int64
+
+	gauges deviceQoS // Isn't a part of ToUsage()
 }
 
 var _ cgroups.ToUsage = &deviceUsage{}
@@ -248,9 +367,16 @@ func (u *deviceUsage) ToUsage() []cgroups.Usage {
 	return []cgroups.Usage{
 		cgroups.MakeUsage("read operations", &u.reads),
 		cgroups.MakeUsage("write operations", &u.writes),
+		cgroups.MakeUsage("discard operations", &u.discards),
 
 		cgroups.MakeUsage("read bytes", &u.read),
 		cgroups.MakeUsage("written bytes", &u.written),
+		cgroups.MakeUsage("discarded bytes", &u.discarded),
+
+		cgroups.MakeUsage("iocost usage", &u.costUsage),
+		cgroups.MakeUsage("iocost wait", &u.costWait),
+		cgroups.MakeUsage("iocost indebt", &u.costIndebt),
+		cgroups.MakeUsage("iocost indelay", &u.costIndelay),
 	}
 }
 
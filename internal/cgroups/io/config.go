@@ -0,0 +1,132 @@
+package io
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+const defaultWeightDevice = "default"
+
+type Config struct {
+	limits  map[string]map[string]int64 // device -> limit type -> value
+	weights map[string]int64            // device -> weight
+}
+
+func (c *Collector) collectConfig(group *cgroups.Group) (Config, bool, error) {
+	var config Config
+
+	// Each file is provided by its own I/O controller policy (blk-throttle, blk-iocost) which may be disabled in the
+	// kernel or not enabled for the group.
+	for _, property := range []struct {
+		name   string
+		parser func(reader io.Reader) error
+	}{
+		{"io.max", func(reader io.Reader) (err error) {
+			config.limits, err = parseMax(reader)
+			return
+		}},
+		{"io.weight", func(reader io.Reader) (err error) {
+			config.weights, err = parseWeight(reader)
+			return
+		}},
+	} {
+		if enabled, err := group.HasProperty(property.name); err != nil {
+			return Config{}, false, err
+		} else if !enabled {
+			continue
+		}
+
+		if exists, err := group.ReadProperty(property.name, property.parser); err != nil || !exists {
+			return Config{}, exists, err
+		}
+	}
+
+	return config, true, nil
+}
+
+// io.max has "$MAJ:$MIN rbps=$VALUE wbps=$VALUE riops=$VALUE wiops=$VALUE" format where $VALUE may be "max"
+func parseMax(reader io.Reader) (map[string]map[string]int64, error) {
+	devices, err := parseKeyValues(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]map[string]int64, len(devices))
+
+	for device, values := range devices {
+		deviceLimits := make(map[string]int64)
+		limits[device] = deviceLimits
+
+		for name, value := range values {
+			if value == "max" {
+				continue
+			}
+
+			limit, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Got an unexpected %s limit value for %s device: %q", name, device, value)
+			}
+			deviceLimits[name] = limit
+		}
+	}
+
+	return limits, nil
+}
+
+// io.weight has "default $WEIGHT" line followed by "$MAJ:$MIN $WEIGHT" lines
+func parseWeight(reader io.Reader) (map[string]int64, error) {
+	weights := make(map[string]int64)
+
+	if err := util.ParseFile(reader, func(line string) error {
+		tokens := strings.Split(line, " ")
+		if len(tokens) != 2 {
+			return fmt.Errorf("Got an unexpected io.weight line: %q", line)
+		}
+
+		device := tokens[0]
+		if _, ok := weights[device]; ok {
+			return fmt.Errorf("Got a duplicated %q device", device)
+		}
+
+		weight, err := strconv.ParseInt(tokens[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("Got an unexpected io.weight line: %q", line)
+		}
+		weights[device] = weight
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return weights, nil
+}
+
+func (c *Collector) recordConfig(ctx context.Context, service string, config Config, metrics chan<- prometheus.Metric) {
+	for device, limits := range config.limits {
+		device = c.resolver.getDeviceName(ctx, device)
+
+		for name, limit := range limits {
+			logging.L(ctx).Debugf("* %s: %s: %s limit=%d", service, device, name, limit)
+			metrics <- prometheus.MustNewConstMetric(limitMetric, prometheus.GaugeValue, float64(limit), service, device, name)
+		}
+	}
+
+	for device, weight := range config.weights {
+		if device != defaultWeightDevice {
+			device = c.resolver.getDeviceName(ctx, device)
+		}
+
+		logging.L(ctx).Debugf("* %s: %s: weight=%d", service, device, weight)
+		metrics <- prometheus.MustNewConstMetric(weightMetric, prometheus.GaugeValue, float64(weight), service, device)
+	}
+}
@@ -0,0 +1,30 @@
+package io
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMax(t *testing.T) {
+	limits, err := parseMax(strings.NewReader(heredoc.Doc(`
+		8:16 rbps=2097152 wbps=max riops=max wiops=120
+		8:0 rbps=max wbps=max riops=max wiops=max
+	`)))
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]int64{
+		"8:16": {"rbps": 2097152, "wiops": 120},
+		"8:0":  {},
+	}, limits)
+}
+
+func TestParseWeight(t *testing.T) {
+	weights, err := parseWeight(strings.NewReader(heredoc.Doc(`
+		default 100
+		8:16 200
+	`)))
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"default": 100, "8:16": 200}, weights)
+}
@@ -0,0 +1,99 @@
+package io
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+// Collects I/O usage from cgroup v1 blkio controller. blk-throttle statistics is used since CFQ one is gone with
+// legacy I/O schedulers. QoS statistics isn't available in this mode.
+func (c *Collector) collectLegacy(group *cgroups.Group) (Usage, bool, error) {
+	// Non-recursive variants account only the group's own processes
+	operations, exists, err := readLegacyStat(group, "blkio.throttle.io_serviced_recursive")
+	if err != nil || !exists {
+		return Usage{}, exists, err
+	}
+
+	bytes, exists, err := readLegacyStat(group, "blkio.throttle.io_service_bytes_recursive")
+	if err != nil || !exists {
+		return Usage{}, exists, err
+	}
+
+	usage := make(Usage, len(bytes))
+
+	for device, stat := range bytes {
+		// Skip all loop devices since they aren't interesting for us and temporary by their nature
+		if strings.HasPrefix(device, "7:") {
+			continue
+		}
+
+		ops := operations[device]
+
+		// Discard statistics appeared in Linux 4.19
+		usage[device] = &deviceUsage{
+			reads:    ops["Read"],
+			writes:   ops["Write"],
+			discards: ops["Discard"],
+
+			read:      stat["Read"],
+			written:   stat["Write"],
+			discarded: stat["Discard"],
+		}
+	}
+
+	return usage, true, nil
+}
+
+func readLegacyStat(group *cgroups.Group, name string) (map[string]map[string]int64, bool, error) {
+	var stat map[string]map[string]int64
+
+	exists, err := group.ReadProperty(name, func(file io.Reader) (err error) {
+		stat, err = parseLegacyStat(file)
+		return
+	})
+
+	return stat, exists, err
+}
+
+// Parses blkio.throttle.io_service* files which have "$MAJOR:$MINOR $OPERATION $VALUE" format
+func parseLegacyStat(reader io.Reader) (map[string]map[string]int64, error) {
+	stat := make(map[string]map[string]int64)
+
+	err := util.ParseFile(reader, func(line string) error {
+		tokens := strings.Split(line, " ")
+
+		// Skip the final "Total $VALUE" line
+		if len(tokens) == 2 && tokens[0] == "Total" {
+			return nil
+		} else if len(tokens) != 3 {
+			return fmt.Errorf("Got an unexpected stat line: %q", line)
+		}
+
+		device, operation := tokens[0], tokens[1]
+
+		value, err := strconv.ParseInt(tokens[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("Got an unexpected stat line: %q", line)
+		}
+
+		deviceStat, ok := stat[device]
+		if !ok {
+			deviceStat = make(map[string]int64)
+			stat[device] = deviceStat
+		}
+
+		if _, ok := deviceStat[operation]; ok {
+			return fmt.Errorf("Got a duplicated %q key for %s device", operation, device)
+		}
+		deviceStat[operation] = value
+
+		return nil
+	})
+
+	return stat, err
+}
@@ -0,0 +1,38 @@
+package io
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLegacyStat(t *testing.T) {
+	stat, err := parseLegacyStat(strings.NewReader(
+		"8:0 Read 2473984\n" +
+			"8:0 Write 1150976\n" +
+			"8:0 Sync 3330048\n" +
+			"8:0 Async 294912\n" +
+			"8:0 Discard 0\n" +
+			"8:0 Total 3624960\n" +
+			"7:1 Read 1024\n" +
+			"7:1 Write 0\n" +
+			"7:1 Sync 1024\n" +
+			"7:1 Async 0\n" +
+			"7:1 Discard 0\n" +
+			"7:1 Total 1024\n" +
+			"Total 3625984\n",
+	))
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]int64{
+		"8:0": {"Read": 2473984, "Write": 1150976, "Sync": 3330048, "Async": 294912, "Discard": 0, "Total": 3624960},
+		"7:1": {"Read": 1024, "Write": 0, "Sync": 1024, "Async": 0, "Discard": 0, "Total": 1024},
+	}, stat)
+
+	stat, err = parseLegacyStat(strings.NewReader("Total 0\n"))
+	require.NoError(t, err)
+	require.Empty(t, stat)
+
+	_, err = parseLegacyStat(strings.NewReader("8:0 Read\n"))
+	require.Error(t, err)
+}
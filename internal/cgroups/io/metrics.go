@@ -1,12 +1,36 @@
 package io
 
 import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
 	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
 )
 
 var metricBuilder = metrics.MakeDescBuilder("services_blkio").WithLabels("service", "device")
 
-var readsMetric = metricBuilder.Build("reads", "Number of read operations issued to the disk by the service.", nil)
-var writesMetric = metricBuilder.Build("writes", "Number of write operations issued to the disk by the service.", nil)
-var readBytesMetric = metricBuilder.Build("read_bytes", "Number of bytes read from the disk by the service.", nil)
-var writtenBytesMetric = metricBuilder.Build("written_bytes", "Number of bytes written to the disk by the service.", nil)
+var readsMetric = cgroups.SumAggregated(metricBuilder.Build("reads", "Number of read operations issued to the disk by the service.", nil))
+var writesMetric = cgroups.SumAggregated(metricBuilder.Build("writes", "Number of write operations issued to the disk by the service.", nil))
+var readBytesMetric = cgroups.SumAggregated(metricBuilder.Build("read_bytes", "Number of bytes read from the disk by the service.", nil))
+var writtenBytesMetric = cgroups.SumAggregated(metricBuilder.Build("written_bytes", "Number of bytes written to the disk by the service.", nil))
+var discardsMetric = cgroups.SumAggregated(metricBuilder.Build("discards", "Number of discard operations issued to the disk by the service.", nil))
+var discardedBytesMetric = cgroups.SumAggregated(metricBuilder.Build("discarded_bytes", "Number of bytes discarded on the disk by the service.", nil))
+
+var limitMetric = metricBuilder.Build("limit", "I/O limits configured for the service (unlimited ones aren't reported).", []string{"type"})
+var weightMetric = metricBuilder.Build("weight", "I/O weight of the service (the default weight is reported for \"default\" device).", nil)
+
+var costUsageMetric = cgroups.SumAggregated(metricBuilder.Build("cost_usage", "Device time consumed by the service according to blk-iocost.", nil))
+var costWaitMetric = cgroups.SumAggregated(metricBuilder.Build("cost_wait", "Time the service's I/O has been waiting for budget according to blk-iocost.", nil))
+var costIndebtMetric = cgroups.SumAggregated(metricBuilder.Build("cost_indebt", "Time the service has spent in debt according to blk-iocost.", nil))
+var costIndelayMetric = cgroups.SumAggregated(metricBuilder.Build("cost_indelay", "Time the service has been delayed due to debt according to blk-iocost.", nil))
+
+var latencyDepthMetric = metricBuilder.Build("latency_depth", "Current io.latency queue depth limit of the service (unlimited one isn't reported).", nil)
+var latencyAverageMetric = cgroups.MaxAggregated(metricBuilder.Build("latency_average", "Average I/O latency of the service according to io.latency.", nil))
+var latencyWindowMetric = metricBuilder.Build("latency_window", "io.latency sampling window.", nil)
+
+var costModelMetric = metrics.MakeDescBuilder("blkio").WithLabels("device").Build(
+	"cost_model_info", "blk-iocost device cost model (see io.cost.model).", costModelLabels)
+
+var costQoSMetric = metrics.MakeDescBuilder("blkio").WithLabels("device").Build(
+	"cost_qos_info", "blk-iocost device QoS configuration (see io.cost.qos).", costQoSLabels)
+
+var costVrateMetric = metrics.MakeDescBuilder("blkio").WithLabels("device").Build(
+	"cost_vrate", "Current blk-iocost device virtual rate (1 means 100%).", nil)
@@ -0,0 +1,190 @@
+package io
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/mo"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+// QoS statistics are present in io.stat only when blk-iocost or io.latency is enabled
+type deviceQoS struct {
+	cost    bool               // Whether iocost statistics are present
+	debt    bool               // Whether iocost debt statistics are present (requires blkcg_debug_stats)
+	vrate   mo.Option[float64] // iocost virtual rate (reported only for root cgroup)
+	latency mo.Option[latencyStat]
+}
+
+type latencyStat struct {
+	depth   mo.Option[int64] // None means unlimited
+	average int64            // In microseconds
+	window  int64            // In milliseconds
+}
+
+func (u *deviceUsage) parseQoS(stat *cgroupsutil.Stat) {
+	if value, ok := stat.Lookup("cost.usage"); ok {
+		u.gauges.cost = true
+		u.costUsage = value
+	}
+
+	// Wait and debt statistics are reported only when the kernel is booted with blkcg_debug_stats
+	if wait, ok := stat.Lookup("cost.wait"); ok {
+		u.gauges.debt = true
+		u.costWait = wait
+		u.costIndebt, _ = stat.Lookup("cost.indebt")
+		u.costIndelay, _ = stat.Lookup("cost.indelay")
+	}
+
+	if vrate, ok := stat.LookupFloat("cost.vrate"); ok {
+		u.gauges.vrate = mo.Some(vrate)
+	}
+
+	if average, ok := stat.Lookup("avg_lat"); ok {
+		latency := latencyStat{average: average}
+		latency.window, _ = stat.Lookup("win")
+		if depth, ok := stat.Lookup("depth"); ok {
+			latency.depth = mo.Some(depth)
+		}
+		u.gauges.latency = mo.Some(latency)
+	}
+}
+
+func (u Usage) qos() map[string]deviceQoS {
+	qos := make(map[string]deviceQoS, len(u))
+	for device, usage := range u {
+		qos[device] = usage.gauges
+	}
+	return qos
+}
+
+func (c *Collector) recordQoS(
+	ctx context.Context, service string, device string, usage *deviceUsage, qos deviceQoS,
+	metrics chan<- prometheus.Metric,
+) {
+	const usec = 1_000_000
+	const msec = 1_000
+
+	if qos.cost {
+		logging.L(ctx).Debugf("* %s: %s: iocost: usage=%d", service, device, usage.costUsage)
+		metrics <- prometheus.MustNewConstMetric(costUsageMetric, prometheus.CounterValue, float64(usage.costUsage)/usec, service, device)
+	}
+
+	if qos.debt {
+		logging.L(ctx).Debugf(
+			"* %s: %s: iocost: wait=%d, indebt=%d, indelay=%d",
+			service, device, usage.costWait, usage.costIndebt, usage.costIndelay)
+
+		metrics <- prometheus.MustNewConstMetric(costWaitMetric, prometheus.CounterValue, float64(usage.costWait)/usec, service, device)
+		metrics <- prometheus.MustNewConstMetric(costIndebtMetric, prometheus.CounterValue, float64(usage.costIndebt)/usec, service, device)
+		metrics <- prometheus.MustNewConstMetric(costIndelayMetric, prometheus.CounterValue, float64(usage.costIndelay)/usec, service, device)
+	}
+
+	// Virtual rate is a device-level value, so it's reported without service label
+	if vrate, ok := qos.vrate.Get(); ok {
+		logging.L(ctx).Debugf("%s: iocost: vrate=%.2f%%", device, vrate)
+		metrics <- prometheus.MustNewConstMetric(costVrateMetric, prometheus.GaugeValue, vrate/100, device)
+	}
+
+	if latency, ok := qos.latency.Get(); ok {
+		depthString := "max"
+		if depth, ok := latency.depth.Get(); ok {
+			depthString = fmt.Sprint(depth)
+			metrics <- prometheus.MustNewConstMetric(latencyDepthMetric, prometheus.GaugeValue, float64(depth), service, device)
+		}
+
+		logging.L(ctx).Debugf(
+			"* %s: %s: io.latency: depth=%s, average=%d, window=%d",
+			service, device, depthString, latency.average, latency.window)
+
+		metrics <- prometheus.MustNewConstMetric(latencyAverageMetric, prometheus.GaugeValue, float64(latency.average)/usec, service, device)
+		metrics <- prometheus.MustNewConstMetric(latencyWindowMetric, prometheus.GaugeValue, float64(latency.window)/msec, service, device)
+	}
+}
+
+var (
+	costModelLabels = []string{"ctrl", "model", "rbps", "rseqiops", "rrandiops", "wbps", "wseqiops", "wrandiops"}
+	costQoSLabels   = []string{"enable", "ctrl", "rpct", "rlat", "wpct", "wlat", "min", "max"}
+)
+
+// Collects device-level iocost configuration which is available only in the root cgroup
+func (c *Collector) collectCostConfig(ctx context.Context, group *cgroups.Group, metrics chan<- prometheus.Metric) error {
+	for _, config := range []struct {
+		name   string
+		metric *prometheus.Desc
+		labels []string
+	}{
+		{"io.cost.model", costModelMetric, costModelLabels},
+		{"io.cost.qos", costQoSMetric, costQoSLabels},
+	} {
+		// The files are missing when the kernel is built without blk-iocost
+		if supported, err := group.HasProperty(config.name); err != nil {
+			return err
+		} else if !supported {
+			continue
+		}
+
+		var devices map[string]map[string]string
+
+		if exists, err := group.ReadProperty(config.name, func(file io.Reader) (err error) {
+			devices, err = parseKeyValues(file)
+			return
+		}); err != nil {
+			return err
+		} else if !exists {
+			continue
+		}
+
+		for device, values := range devices {
+			device = c.resolver.getDeviceName(ctx, device)
+			logging.L(ctx).Debugf("%s: %s: %v", config.name, device, values)
+
+			labels := []string{device}
+			for _, name := range config.labels {
+				labels = append(labels, values[name])
+			}
+
+			metrics <- prometheus.MustNewConstMetric(config.metric, prometheus.GaugeValue, 1, labels...)
+		}
+	}
+
+	return nil
+}
+
+// Parses "$MAJ:$MIN key=value..." lines
+func parseKeyValues(reader io.Reader) (map[string]map[string]string, error) {
+	devices := make(map[string]map[string]string)
+
+	if err := util.ParseFile(reader, func(line string) error {
+		tokens := strings.Split(line, " ")
+
+		device := tokens[0]
+		if _, ok := devices[device]; ok {
+			return fmt.Errorf("Got a duplicated %q device", device)
+		}
+
+		values := make(map[string]string)
+		devices[device] = values
+
+		for _, token := range tokens[1:] {
+			name, value, ok := strings.Cut(token, "=")
+			if !ok {
+				return fmt.Errorf("Got an unexpected line: %q", line)
+			}
+			values[name] = value
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
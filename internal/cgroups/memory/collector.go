@@ -3,9 +3,6 @@ package memory
 import (
 	"context"
 	"fmt"
-	"io"
-	"strconv"
-	"strings"
 
 	logging "github.com/KonishchevDmitry/go-easy-logging"
 	"github.com/pkg/math"
@@ -16,13 +13,19 @@ import (
 )
 
 type Collector struct {
-	races *cgroups.RaceController
+	detailed bool
+	roots    map[string]*rootState
+	races    *cgroups.RaceController
 }
 
 var _ cgroups.Collector = &Collector{}
 
-func NewCollector(races *cgroups.RaceController) *Collector {
-	return &Collector{races: races}
+func NewCollector(races *cgroups.RaceController, detailed bool) *Collector {
+	return &Collector{
+		detailed: detailed,
+		roots:    make(map[string]*rootState),
+		races:    races,
+	}
 }
 
 func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
@@ -30,24 +33,45 @@ func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
 	descs <- swapMetric
 	descs <- cacheMetric
 	descs <- kernelMetric
+
+	if c.detailed {
+		descs <- statMetric
+		descs <- statEventsMetric
+	}
+
+	descs <- limitMetric
+	descs <- limitUtilizationMetric
+	descs <- peakMetric
 }
 
 func (c *Collector) Pre() {
+	for _, state := range c.roots {
+		state.collected = false
+	}
 }
 
 func (c *Collector) Post(ctx context.Context) {
+	for name, state := range c.roots {
+		if !state.collected {
+			if cgroups.NewGroup(name, c.races).IsRoot() {
+				logging.L(ctx).Errorf("memory: %q hasn't been collected.", name)
+			} else {
+				logging.L(ctx).Debugf("memory: %q root hasn't been collected. Assuming it deleted and dropping its state.", name)
+				delete(c.roots, name)
+			}
+		}
+	}
 }
 
 func (c *Collector) Collect(
 	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
 ) (bool, error) {
-	usage, exists, err := c.collect(group)
-	if err != nil || !exists {
-		return exists, err
-	}
-
-	var isRoot bool
-	var children []*cgroups.Group
+	var (
+		isRoot   bool
+		children []*cgroups.Group
+		exists   bool
+		err      error
+	)
 
 	if group.IsRoot() {
 		isRoot = true
@@ -62,14 +86,61 @@ func (c *Collector) Collect(
 		}
 	}
 
+	collect := c.collect
+	detailed := c.detailed
+
+	legacy, isLegacy := group.Legacy("memory").Get()
+	if isLegacy {
+		// Legacy hierarchy has no group if memory accounting isn't enabled for the service. Its usage is accounted to
+		// the parent group in this case.
+		if exists, err := legacy.IsExist(); err != nil {
+			return false, err
+		} else if !exists {
+			logging.L(ctx).Debugf("* %s: memory: memory accounting is disabled.", service)
+			return true, nil
+		}
+
+		group = legacy
+		for index, child := range children {
+			children[index] = child.Legacy("memory").MustGet()
+		}
+
+		collect = c.collectLegacy
+		detailed = false
+	} else if !group.IsRoot() {
+		// Threaded groups (libvirt's vcpu threads for example) have no domain controllers. Their usage is accounted to
+		// the nearest domain group.
+		if enabled, exists, err := group.HasController("memory"); err != nil || !exists {
+			return exists, err
+		} else if !enabled {
+			logging.L(ctx).Debugf("* %s: memory: memory controller is disabled.", service)
+			return true, nil
+		}
+	}
+
+	usage, exists, err := collect(group)
+	if err != nil || !exists {
+		return exists, err
+	}
+
 	if isRoot {
-		usage, exists, err = c.collectRoot(group, usage, children)
+		usage, exists, err = c.collectRoot(group, usage, children, collect)
 		if err != nil || !exists {
 			return exists, err
 		}
 	}
 
-	c.record(ctx, service, usage, metrics)
+	c.record(ctx, service, usage, detailed, metrics)
+
+	// Root cgroup has no memory limits. Legacy limits aren't supported.
+	if !group.IsRoot() && !isLegacy {
+		limits, exists, err := c.collectLimits(group, exclude)
+		if err != nil || !exists {
+			return exists, err
+		}
+		c.recordLimits(ctx, service, limits, metrics)
+	}
+
 	return true, nil
 }
 
@@ -81,14 +152,8 @@ func (c *Collector) collect(group *cgroups.Group) (Usage, bool, error) {
 
 	var swap int64
 	if !group.IsRoot() {
-		if exists, err := group.ReadProperty("memory.swap.current", func(file io.Reader) error {
-			data, err := io.ReadAll(file)
-			if err == nil {
-				value := strings.TrimSpace(string(data))
-				swap, err = strconv.ParseInt(value, 10, 64)
-			}
-			return err
-		}); err != nil || !exists {
+		swap, exists, err = cgroupsutil.ReadValue(group, "memory.swap.current")
+		if err != nil || !exists {
 			return Usage{}, exists, err
 		}
 	}
@@ -101,6 +166,13 @@ func (c *Collector) collect(group *cgroups.Group) (Usage, bool, error) {
 		}
 		return value
 	}
+	getDetailed := func(field detailedField) int64 {
+		if field.optional {
+			value, _ := stat.Lookup(field.name)
+			return value
+		}
+		return get(field.name)
+	}
 
 	usage := Usage{
 		rss:    get("anon"),
@@ -108,6 +180,16 @@ func (c *Collector) collect(group *cgroups.Group) (Usage, bool, error) {
 		cache:  get("file"),
 		kernel: get("kernel_stack") + get("pagetables") + get("percpu") + get("slab_unreclaimable") + get("sock"),
 	}
+
+	if c.detailed {
+		for index, field := range detailedStatFields {
+			usage.stat[index] = getDetailed(field)
+		}
+		for index, field := range detailedEventFields {
+			usage.events[index] = getDetailed(field)
+		}
+	}
+
 	if keyErr != nil {
 		return Usage{}, true, keyErr
 	}
@@ -115,15 +197,39 @@ func (c *Collector) collect(group *cgroups.Group) (Usage, bool, error) {
 	return usage, true, nil
 }
 
-func (c *Collector) collectRoot(group *cgroups.Group, usage Usage, children []*cgroups.Group) (Usage, bool, error) {
+func (c *Collector) collectRoot(
+	group *cgroups.Group, usage Usage, children []*cgroups.Group, collect func(group *cgroups.Group) (Usage, bool, error),
+) (Usage, bool, error) {
+	// Gauges are calculated as a simple difference between the group and its children, but counters must stay
+	// monotonic, so they are calculated the same way as CPU usage.
 	rootUsages := usage.ToUsage()
+	currentEvents := rootEvents{root: usage.events}
+	_, legacy := group.Legacy("memory").Get()
 
 	for _, child := range children {
-		childUsage, childExists, err := c.collect(child)
+		// Memory accounting may be disabled for the child, so check its existence explicitly to not report the missing
+		// group to the race controller
+		if legacy {
+			if exists, err := child.IsExist(); err != nil {
+				return Usage{}, false, err
+			} else if !exists {
+				continue
+			}
+		} else if enabled, exists, err := child.HasController("memory"); err != nil {
+			return Usage{}, false, err
+		} else if exists && !enabled {
+			// Threaded child's usage is accounted to the group itself
+			continue
+		}
+
+		childUsage, childExists, err := collect(child)
 		if err != nil {
 			return Usage{}, false, err
 		} else if !childExists {
-			if group.IsRoot() {
+			if legacy {
+				// The child has been deleted during metrics collection
+				continue
+			} else if group.IsRoot() {
 				return Usage{}, false, fmt.Errorf("%q has been deleted during metrics collection", child.Path())
 			} else {
 				return Usage{}, false, c.races.Check(group, fmt.Errorf(
@@ -135,12 +241,29 @@ func (c *Collector) collectRoot(group *cgroups.Group, usage Usage, children []*c
 			rootUsage := rootUsages[index]
 			*rootUsage.Value = math.MaxInt64(0, *rootUsage.Value-*childUsage.Value)
 		}
+		cgroups.AddUsage(&currentEvents.children, &childUsage.events)
+	}
+
+	state, ok := c.roots[group.Name]
+	if ok {
+		if err := cgroups.CalculateRootGroupUsage(&state.netEvents, &currentEvents, &state.lastEvents); err != nil {
+			return Usage{}, false, err
+		}
+	} else {
+		state = &rootState{}
+		c.roots[group.Name] = state
 	}
 
+	state.lastEvents = currentEvents
+	state.collected = true
+	usage.events = state.netEvents
+
 	return usage, true, nil
 }
 
-func (c *Collector) record(ctx context.Context, service string, usage Usage, metrics chan<- prometheus.Metric) {
+func (c *Collector) record(
+	ctx context.Context, service string, usage Usage, detailed bool, metrics chan<- prometheus.Metric,
+) {
 	logging.L(ctx).Debugf(
 		"* %s: memory: rss=%d, swap=%d, cache=%d, kernel=%d",
 		service, usage.rss, usage.swap, usage.cache, usage.kernel)
@@ -149,6 +272,22 @@ func (c *Collector) record(ctx context.Context, service string, usage Usage, met
 	metrics <- prometheus.MustNewConstMetric(swapMetric, prometheus.GaugeValue, float64(usage.swap), service)
 	metrics <- prometheus.MustNewConstMetric(cacheMetric, prometheus.GaugeValue, float64(usage.cache), service)
 	metrics <- prometheus.MustNewConstMetric(kernelMetric, prometheus.GaugeValue, float64(usage.kernel), service)
+
+	if !detailed {
+		return
+	}
+
+	for index, field := range detailedStatFields {
+		value := usage.stat[index]
+		logging.L(ctx).Debugf("* %s: memory: %s=%d", service, field.name, value)
+		metrics <- prometheus.MustNewConstMetric(statMetric, prometheus.GaugeValue, float64(value), service, field.name)
+	}
+
+	for index, field := range detailedEventFields {
+		value := usage.events[index]
+		logging.L(ctx).Debugf("* %s: memory: %s=%d", service, field.name, value)
+		metrics <- prometheus.MustNewConstMetric(statEventsMetric, prometheus.CounterValue, float64(value), service, field.name)
+	}
 }
 
 type Usage struct {
@@ -156,15 +295,81 @@ type Usage struct {
 	swap   int64
 	cache  int64
 	kernel int64
+
+	stat   detailedStat
+	events detailedEvents // Isn't a part of ToUsage() since requires a special handling for root groups
 }
 
 var _ cgroups.ToUsage = &Usage{}
 
 func (u *Usage) ToUsage() []cgroups.Usage {
-	return []cgroups.Usage{
+	usages := []cgroups.Usage{
 		cgroups.MakeUsage("rss memory usage", &u.rss),
 		cgroups.MakeUsage("non-cached swap usage", &u.swap),
 		cgroups.MakeUsage("page cache usage", &u.cache),
 		cgroups.MakeUsage("kernel memory usage", &u.kernel),
 	}
+	for index, field := range detailedStatFields {
+		usages = append(usages, cgroups.MakeUsage(field.name+" memory usage", &u.stat[index]))
+	}
+	return usages
+}
+
+type detailedField struct {
+	name     string
+	optional bool
+}
+
+var detailedStatFields = [...]detailedField{
+	{name: "file_dirty"},
+	{name: "file_writeback"},
+	{name: "file_mapped"},
+	{name: "shmem"},
+	{name: "anon_thp"},
+	{name: "active_anon"},
+	{name: "inactive_anon"},
+	{name: "active_file"},
+	{name: "inactive_file"},
+	{name: "zswap", optional: true},    // Appeared in Linux 5.19
+	{name: "zswapped", optional: true}, // Appeared in Linux 5.19
+}
+
+type detailedStat [len(detailedStatFields)]int64
+
+var detailedEventFields = [...]detailedField{
+	{name: "workingset_refault_anon"},
+	{name: "workingset_refault_file"},
+	{name: "pgfault"},
+	{name: "pgmajfault"},
+	{name: "pgscan"},
+	{name: "pgsteal"},
+}
+
+type detailedEvents [len(detailedEventFields)]int64
+
+var _ cgroups.ToUsage = &detailedEvents{}
+
+func (e *detailedEvents) ToUsage() []cgroups.Usage {
+	usages := make([]cgroups.Usage, 0, len(e))
+	for index, field := range detailedEventFields {
+		usages = append(usages, cgroups.MakeUsage(field.name+" memory events", &e[index]))
+	}
+	return usages
+}
+
+type rootEvents struct {
+	root     detailedEvents
+	children detailedEvents
+}
+
+var _ cgroups.ToRootUsage = &rootEvents{}
+
+func (e *rootEvents) ToRootUsage() (cgroups.ToUsage, cgroups.ToUsage) {
+	return &e.root, &e.children
+}
+
+type rootState struct {
+	lastEvents rootEvents
+	netEvents  detailedEvents
+	collected  bool
 }
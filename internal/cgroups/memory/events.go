@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+)
+
+type EventsCollector struct {
+	enabled bool
+	roots   map[string]*eventsRootState
+	races   *cgroups.RaceController
+}
+
+var _ cgroups.Collector = &EventsCollector{}
+
+func NewEventsCollector(races *cgroups.RaceController) *EventsCollector {
+	return &EventsCollector{
+		enabled: cgroups.CurrentHierarchy().HasController("memory"),
+		roots:   make(map[string]*eventsRootState),
+		races:   races,
+	}
+}
+
+func (c *EventsCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- eventsMetric
+	descs <- localEventsMetric
+}
+
+func (c *EventsCollector) Pre() {
+	for _, state := range c.roots {
+		state.collected = false
+	}
+}
+
+func (c *EventsCollector) Post(ctx context.Context) {
+	for name, state := range c.roots {
+		if !state.collected {
+			logging.L(ctx).Debugf("memory events: %q root hasn't been collected. Assuming it deleted and dropping its state.", name)
+			delete(c.roots, name)
+		}
+	}
+}
+
+func (c *EventsCollector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	// Root cgroup has no memory controller interface files
+	if !c.enabled || group.IsRoot() {
+		return true, nil
+	}
+
+	// Threaded groups have no memory controller
+	if enabled, exists, err := group.HasController("memory"); err != nil || !exists {
+		return exists, err
+	} else if !enabled {
+		return true, nil
+	}
+
+	events, exists, err := c.collect(group, "memory.events")
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	localEvents, exists, err := c.collect(group, "memory.events.local")
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	if len(exclude) != 0 {
+		var children []*cgroups.Group
+		for _, name := range exclude {
+			children = append(children, group.Child(name))
+		}
+
+		events, exists, err = c.collectRoot(group, events, children)
+		if err != nil || !exists {
+			return exists, err
+		}
+	}
+
+	c.record(ctx, service, events, localEvents, metrics)
+	return true, nil
+}
+
+func (c *EventsCollector) collect(group *cgroups.Group, name string) (Events, bool, error) {
+	stat, exists, err := cgroupsutil.ReadStat(group, name)
+	if err != nil || !exists {
+		return Events{}, exists, err
+	}
+
+	var events Events
+
+	for _, event := range events.events() {
+		if value, ok := stat.Lookup(event.name); ok {
+			*event.value = value
+		} else if !event.optional {
+			return Events{}, true, fmt.Errorf("%q entry of %s is missing", event.name, name)
+		}
+	}
+
+	return events, true, nil
+}
+
+// Event counters are hierarchical, so the excluded children's events are subtracted from the group's ones. The
+// counters must stay monotonic, so they are calculated the same way as CPU usage.
+func (c *EventsCollector) collectRoot(group *cgroups.Group, events Events, children []*cgroups.Group) (Events, bool, error) {
+	current := eventsRootUsage{root: events}
+
+	for _, child := range children {
+		// Threaded child's events are accounted to the group itself
+		if enabled, exists, err := child.HasController("memory"); err != nil {
+			return Events{}, false, err
+		} else if exists && !enabled {
+			continue
+		}
+
+		childEvents, childExists, err := c.collect(child, "memory.events")
+		if err != nil {
+			return Events{}, false, err
+		} else if !childExists {
+			return Events{}, false, c.races.Check(group, fmt.Errorf(
+				"%q is missing, but is expected to exist", child.Path()))
+		}
+		cgroups.AddUsage(&current.children, &childEvents)
+	}
+
+	state, ok := c.roots[group.Name]
+	if ok {
+		if err := cgroups.CalculateRootGroupUsage(&state.netEvents, &current, &state.lastUsage); err != nil {
+			return Events{}, false, err
+		}
+	} else {
+		state = &eventsRootState{}
+		c.roots[group.Name] = state
+	}
+
+	state.lastUsage = current
+	state.collected = true
+
+	return state.netEvents, true, nil
+}
+
+func (c *EventsCollector) record(
+	ctx context.Context, service string, events Events, localEvents Events, metrics chan<- prometheus.Metric,
+) {
+	logging.L(ctx).Debugf(
+		"* %s: memory events: low=%d, high=%d, max=%d, oom=%d, oom_kill=%d, oom_group_kill=%d",
+		service, events.low, events.high, events.max, events.oom, events.oomKill, events.oomGroupKill)
+
+	for _, event := range events.events() {
+		metrics <- prometheus.MustNewConstMetric(eventsMetric, prometheus.CounterValue, float64(*event.value), service, event.name)
+	}
+
+	logging.L(ctx).Debugf(
+		"* %s: local memory events: low=%d, high=%d, max=%d, oom=%d, oom_kill=%d, oom_group_kill=%d",
+		service, localEvents.low, localEvents.high, localEvents.max, localEvents.oom, localEvents.oomKill, localEvents.oomGroupKill)
+
+	for _, event := range localEvents.events() {
+		metrics <- prometheus.MustNewConstMetric(localEventsMetric, prometheus.CounterValue, float64(*event.value), service, event.name)
+	}
+}
+
+type Events struct {
+	low          int64
+	high         int64
+	max          int64
+	oom          int64
+	oomKill      int64
+	oomGroupKill int64
+}
+
+type event struct {
+	name     string
+	value    *int64
+	optional bool
+}
+
+func (e *Events) events() []event {
+	return []event{
+		{name: "low", value: &e.low},
+		{name: "high", value: &e.high},
+		{name: "max", value: &e.max},
+		{name: "oom", value: &e.oom},
+		{name: "oom_kill", value: &e.oomKill},
+		{name: "oom_group_kill", value: &e.oomGroupKill, optional: true}, // Appeared in Linux 5.17
+	}
+}
+
+var _ cgroups.ToUsage = &Events{}
+
+func (e *Events) ToUsage() []cgroups.Usage {
+	events := e.events()
+	usages := make([]cgroups.Usage, 0, len(events))
+	for _, event := range events {
+		usages = append(usages, cgroups.MakeUsage(event.name+" memory events", event.value))
+	}
+	return usages
+}
+
+type eventsRootUsage struct {
+	root     Events
+	children Events
+}
+
+var _ cgroups.ToRootUsage = &eventsRootUsage{}
+
+func (u *eventsRootUsage) ToRootUsage() (cgroups.ToUsage, cgroups.ToUsage) {
+	return &u.root, &u.children
+}
+
+type eventsRootState struct {
+	lastUsage eventsRootUsage
+	netEvents Events
+	collected bool
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"github.com/pkg/math"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+)
+
+// Collects memory usage from cgroup v1 memory controller. Detailed statistics isn't supported in this mode.
+func (c *Collector) collectLegacy(group *cgroups.Group) (Usage, bool, error) {
+	stat, exists, err := cgroupsutil.ReadStat(group, "memory.stat")
+	if err != nil || !exists {
+		return Usage{}, exists, err
+	}
+
+	// total_* values include usage of all descendants as cgroup v2 ones do
+	rss, err := stat.Get("total_rss")
+	if err != nil {
+		return Usage{}, true, err
+	}
+
+	cache, err := stat.Get("total_cache")
+	if err != nil {
+		return Usage{}, true, err
+	}
+
+	// Swap statistics is available only when swap accounting is enabled, swap cache one – since Linux 5.11
+	swap, _ := stat.Lookup("total_swap")
+	swapCached, _ := stat.Lookup("total_swapcached")
+
+	// Kernel memory accounting may be disabled
+	var kernel int64
+	if enabled, err := group.HasProperty("memory.kmem.usage_in_bytes"); err != nil {
+		return Usage{}, true, err
+	} else if enabled {
+		kernel, exists, err = cgroupsutil.ReadValue(group, "memory.kmem.usage_in_bytes")
+		if err != nil || !exists {
+			return Usage{}, exists, err
+		}
+	}
+
+	return Usage{
+		rss:    rss,
+		swap:   math.MaxInt64(0, swap-swapCached),
+		cache:  cache,
+		kernel: kernel,
+	}, true, nil
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/mo"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+)
+
+type Limits struct {
+	current int64
+	peak    mo.Option[int64]
+	limits  []limit
+}
+
+type limit struct {
+	name  string
+	value mo.Option[int64] // None means no limit
+}
+
+var limitFiles = []struct {
+	name string
+	file string
+}{
+	{"max", "memory.max"},
+	{"high", "memory.high"},
+	{"low", "memory.low"},
+	{"min", "memory.min"},
+	{"swap_max", "memory.swap.max"},
+}
+
+func (c *Collector) collectLimits(group *cgroups.Group, exclude []string) (Limits, bool, error) {
+	var limits Limits
+
+	// The limits are enforced on the whole cgroup, so the utilization is calculated from the whole cgroup usage
+	// (memory.current) even for "total excluding" groups: their excluded children are charged against the same limit,
+	// but aren't included in the service's usage metrics.
+	current, exists, err := cgroupsutil.ReadValue(group, "memory.current")
+	if err != nil || !exists {
+		return Limits{}, exists, err
+	}
+	limits.current = current
+
+	for _, limitFile := range limitFiles {
+		value, exists, err := cgroupsutil.ReadLimit(group, limitFile.file)
+		if err != nil || !exists {
+			return Limits{}, exists, err
+		}
+		limits.limits = append(limits.limits, limit{name: limitFile.name, value: value})
+	}
+
+	// Peak usage can't be calculated for the part of the group, so we report it only for the whole groups. Also
+	// memory.peak is available only since Linux 5.19.
+	if len(exclude) == 0 {
+		if peakExists, err := group.HasProperty("memory.peak"); err != nil {
+			return Limits{}, true, err
+		} else if peakExists {
+			peak, exists, err := cgroupsutil.ReadLimit(group, "memory.peak")
+			if err != nil || !exists {
+				return Limits{}, exists, err
+			}
+			limits.peak = peak
+		}
+	}
+
+	return limits, true, nil
+}
+
+func (c *Collector) recordLimits(ctx context.Context, service string, limits Limits, metrics chan<- prometheus.Metric) {
+	var buf strings.Builder
+	_, _ = fmt.Fprintf(&buf, "* %s: memory limits: current=%d", service, limits.current)
+
+	for _, limit := range limits.limits {
+		value, ok := limit.value.Get()
+		if !ok {
+			_, _ = fmt.Fprintf(&buf, ", %s=max", limit.name)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(&buf, ", %s=%d", limit.name, value)
+		metrics <- prometheus.MustNewConstMetric(limitMetric, prometheus.GaugeValue, float64(value), service, limit.name)
+
+		if limit.name == "max" && value != 0 {
+			metrics <- prometheus.MustNewConstMetric(
+				limitUtilizationMetric, prometheus.GaugeValue, float64(limits.current)/float64(value), service)
+		}
+	}
+
+	if peak, ok := limits.peak.Get(); ok {
+		_, _ = fmt.Fprintf(&buf, ", peak=%d", peak)
+		metrics <- prometheus.MustNewConstMetric(peakMetric, prometheus.GaugeValue, float64(peak), service)
+	}
+
+	logging.L(ctx).Debug(buf.String())
+}
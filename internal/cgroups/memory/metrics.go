@@ -1,12 +1,34 @@
 package memory
 
 import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
 	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
 )
 
 var metricBuilder = metrics.MakeDescBuilder("services_memory").WithLabels("service")
 
-var rssMetric = metricBuilder.Build("rss", "Anonymous and swap cache memory usage.", nil)
-var swapMetric = metricBuilder.Build("swap", "Non-cached swap usage.", nil)
-var cacheMetric = metricBuilder.Build("cache", "Page cache memory usage.", nil)
-var kernelMetric = metricBuilder.Build("kernel", "Kernel data structures.", nil)
+var rssMetric = cgroups.SumAggregated(metricBuilder.Build("rss", "Anonymous and swap cache memory usage.", nil))
+var swapMetric = cgroups.SumAggregated(metricBuilder.Build("swap", "Non-cached swap usage.", nil))
+var cacheMetric = cgroups.SumAggregated(metricBuilder.Build("cache", "Page cache memory usage.", nil))
+var kernelMetric = cgroups.SumAggregated(metricBuilder.Build("kernel", "Kernel data structures.", nil))
+
+var eventsMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"events", "Memory events of the service and all its processes (see memory.events).", []string{"type"}))
+
+var localEventsMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"local_events", "Memory events of the service's own cgroup not including its children (see memory.events.local).", []string{"type"}))
+
+var limitMetric = metricBuilder.Build(
+	"limit", "Memory limits and protections configured for the service (unlimited ones aren't reported).", []string{"type"})
+
+var limitUtilizationMetric = cgroups.MaxAggregated(metricBuilder.Build(
+	"limit_utilization", "Current memory usage of the service's whole cgroup (including excluded children) relative to its memory.max limit.", nil))
+
+var peakMetric = metricBuilder.Build(
+	"peak", "Peak memory usage of the service's cgroup.", nil)
+
+var statMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"stat", "Detailed memory usage breakdown (see memory.stat).", []string{"name"}))
+
+var statEventsMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"stat_events", "Memory management event counters (see memory.stat).", []string{"name"}))
@@ -0,0 +1,138 @@
+package netns
+
+import (
+	"context"
+	"errors"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/mo"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/procfs"
+)
+
+// Collector collects network traffic of services which are running in their own network namespaces (containers,
+// units with PrivateNetwork=, etc.).
+type Collector struct {
+	hostNamespace mo.Option[string]
+	owners        *namespaceOwners
+}
+
+var _ cgroups.Collector = &Collector{}
+
+func NewCollector() *Collector {
+	return &Collector{
+		owners: newNamespaceOwners(),
+	}
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- rxBytesMetric
+	descs <- rxPacketsMetric
+	descs <- rxDroppedMetric
+
+	descs <- txBytesMetric
+	descs <- txPacketsMetric
+	descs <- txDroppedMetric
+}
+
+func (c *Collector) Pre() {
+	c.owners.pre()
+}
+
+func (c *Collector) Post(ctx context.Context) {
+	c.owners.post()
+}
+
+func (c *Collector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	hostNamespace, err := c.getHostNamespace()
+	if err != nil {
+		return true, err
+	}
+
+	pids, exists, err := group.AllPIDs(exclude)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	// A service may have processes in different namespaces, so use the first process from each one to read its stats
+	namespaces := make(map[string]int)
+
+	for _, pid := range pids {
+		namespace, exists, err := procfs.Readlink(pid, "ns/net")
+		if err != nil {
+			return true, err
+		} else if !exists || namespace == hostNamespace {
+			continue
+		}
+
+		if _, ok := namespaces[namespace]; !ok {
+			namespaces[namespace] = pid
+		}
+	}
+
+	for namespace, pid := range namespaces {
+		if !c.owners.observe(namespace, service) {
+			logging.L(ctx).Debugf("* %s: %s network namespace is attributed to another service.", service, namespace)
+			continue
+		}
+
+		data, exists, err := procfs.ReadFile(pid, "net/dev")
+		if err != nil {
+			return true, err
+		} else if !exists {
+			continue
+		}
+
+		stats, err := parseNetDev(data)
+		if err != nil {
+			return true, err
+		}
+
+		c.record(ctx, service, stats, metrics)
+	}
+
+	return true, nil
+}
+
+func (c *Collector) getHostNamespace() (string, error) {
+	if namespace, ok := c.hostNamespace.Get(); ok {
+		return namespace, nil
+	}
+
+	namespace, exists, err := procfs.Readlink(1, "ns/net")
+	if err != nil {
+		return "", err
+	} else if !exists {
+		return "", errors.New("Unable to determine host network namespace: init process is missing")
+	}
+
+	c.hostNamespace = mo.Some(namespace)
+	return namespace, nil
+}
+
+func (c *Collector) record(
+	ctx context.Context, service string, stats map[string]interfaceStat, metrics chan<- prometheus.Metric,
+) {
+	for name, stat := range stats {
+		// Loopback traffic is internal to the service
+		if name == "lo" {
+			continue
+		}
+
+		logging.L(ctx).Debugf(
+			"* %s: %s: rx=%d (%d packets, %d dropped), tx=%d (%d packets, %d dropped)",
+			service, name, stat.rxBytes, stat.rxPackets, stat.rxDropped, stat.txBytes, stat.txPackets, stat.txDropped)
+
+		metrics <- prometheus.MustNewConstMetric(rxBytesMetric, prometheus.CounterValue, float64(stat.rxBytes), service, name)
+		metrics <- prometheus.MustNewConstMetric(rxPacketsMetric, prometheus.CounterValue, float64(stat.rxPackets), service, name)
+		metrics <- prometheus.MustNewConstMetric(rxDroppedMetric, prometheus.CounterValue, float64(stat.rxDropped), service, name)
+
+		metrics <- prometheus.MustNewConstMetric(txBytesMetric, prometheus.CounterValue, float64(stat.txBytes), service, name)
+		metrics <- prometheus.MustNewConstMetric(txPacketsMetric, prometheus.CounterValue, float64(stat.txPackets), service, name)
+		metrics <- prometheus.MustNewConstMetric(txDroppedMetric, prometheus.CounterValue, float64(stat.txDropped), service, name)
+	}
+}
@@ -0,0 +1,16 @@
+package netns
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var metricBuilder = metrics.MakeDescBuilder("services_network").WithLabels("service", "interface")
+
+var rxBytesMetric = cgroups.SumAggregated(metricBuilder.Build("rx_bytes", "Number of bytes received by the service's network namespace.", nil))
+var rxPacketsMetric = cgroups.SumAggregated(metricBuilder.Build("rx_packets", "Number of packets received by the service's network namespace.", nil))
+var rxDroppedMetric = cgroups.SumAggregated(metricBuilder.Build("rx_dropped", "Number of received packets dropped in the service's network namespace.", nil))
+
+var txBytesMetric = cgroups.SumAggregated(metricBuilder.Build("tx_bytes", "Number of bytes sent by the service's network namespace.", nil))
+var txPacketsMetric = cgroups.SumAggregated(metricBuilder.Build("tx_packets", "Number of packets sent by the service's network namespace.", nil))
+var txDroppedMetric = cgroups.SumAggregated(metricBuilder.Build("tx_dropped", "Number of outgoing packets dropped in the service's network namespace.", nil))
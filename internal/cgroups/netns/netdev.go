@@ -0,0 +1,75 @@
+package netns
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+type interfaceStat struct {
+	rxBytes   uint64
+	rxPackets uint64
+	rxDropped uint64
+
+	txBytes   uint64
+	txPackets uint64
+	txDropped uint64
+}
+
+// https://man7.org/linux/man-pages/man5/proc_pid_net.5.html
+func parseNetDev(data []byte) (map[string]interfaceStat, error) {
+	const headerLines = 2
+
+	stats := make(map[string]interfaceStat)
+	var lineNum int
+
+	if err := util.ParseFile(bytes.NewReader(data), func(line string) error {
+		lineNum++
+		if lineNum <= headerLines {
+			return nil
+		}
+
+		name, values, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		fields := strings.Fields(values)
+		if len(fields) != 16 {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		var parseErr error
+		get := func(index int) uint64 {
+			value, err := strconv.ParseUint(fields[index], 10, 64)
+			if err != nil {
+				parseErr = fmt.Errorf("Got an unexpected line: %q", line)
+			}
+			return value
+		}
+
+		name = strings.TrimSpace(name)
+		if _, ok := stats[name]; ok {
+			return fmt.Errorf("Got a duplicated %q interface", name)
+		}
+
+		stats[name] = interfaceStat{
+			rxBytes:   get(0),
+			rxPackets: get(1),
+			rxDropped: get(3),
+
+			txBytes:   get(8),
+			txPackets: get(9),
+			txDropped: get(11),
+		}
+
+		return parseErr
+	}); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,28 @@
+package netns
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetDev(t *testing.T) {
+	stats, err := parseNetDev([]byte(heredoc.Doc(`
+		Inter-|   Receive                                                |  Transmit
+		 face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+		    lo:    4536      54    0    0    0     0          0         0     4536      54    0    0    0     0       0          0
+		  eth0: 9487213    6713    0   12    0     0          0         0   498329    4210    0    3    0     0       0          0
+	`)))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interfaceStat{
+		"lo": {
+			rxBytes: 4536, rxPackets: 54,
+			txBytes: 4536, txPackets: 54,
+		},
+		"eth0": {
+			rxBytes: 9487213, rxPackets: 6713, rxDropped: 12,
+			txBytes: 498329, txPackets: 4210, txDropped: 3,
+		},
+	}, stats)
+}
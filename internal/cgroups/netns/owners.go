@@ -0,0 +1,61 @@
+package netns
+
+// Several services may share one network namespace (Podman pods for example), so its traffic is attributed to only one
+// of them – the one with the smallest name among the services which have observed the namespace during the previous
+// collection. This way the attribution doesn't depend on the cgroups observation order.
+//
+// Namespaces which appear for the first time are attributed to the first observer. When the owner leaves the
+// namespace, the namespace isn't reported until the end of the collection, when a new owner is elected.
+type namespaceOwners struct {
+	owners    map[string]string              // Owners elected at the end of the previous collection
+	observers map[string]map[string]struct{} // Services which have observed the namespace during the current collection
+	reported  map[string]struct{}            // Namespaces reported during the current collection
+}
+
+func newNamespaceOwners() *namespaceOwners {
+	return &namespaceOwners{
+		owners:    make(map[string]string),
+		observers: make(map[string]map[string]struct{}),
+		reported:  make(map[string]struct{}),
+	}
+}
+
+func (o *namespaceOwners) pre() {
+	clear(o.observers)
+	clear(o.reported)
+}
+
+// Registers the service as the namespace observer and returns whether it should report the namespace traffic
+func (o *namespaceOwners) observe(namespace string, service string) bool {
+	observers, ok := o.observers[namespace]
+	if !ok {
+		observers = make(map[string]struct{})
+		o.observers[namespace] = observers
+	}
+	observers[service] = struct{}{}
+
+	if _, ok := o.reported[namespace]; ok {
+		return false
+	}
+
+	if owner, ok := o.owners[namespace]; ok && owner != service {
+		return false
+	}
+
+	o.reported[namespace] = struct{}{}
+	return true
+}
+
+func (o *namespaceOwners) post() {
+	clear(o.owners)
+
+	for namespace, observers := range o.observers {
+		var owner string
+		for service := range observers {
+			if owner == "" || service < owner {
+				owner = service
+			}
+		}
+		o.owners[namespace] = owner
+	}
+}
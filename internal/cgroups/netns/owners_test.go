@@ -0,0 +1,34 @@
+package netns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaceOwners(t *testing.T) {
+	owners := newNamespaceOwners()
+
+	// Unknown namespace is attributed to the first observer
+	owners.pre()
+	require.True(t, owners.observe("net:[1]", "pod-b"))
+	require.False(t, owners.observe("net:[1]", "pod-a"))
+	owners.post()
+
+	// Then to the service with the smallest name regardless of the observation order
+	for range 2 {
+		owners.pre()
+		require.False(t, owners.observe("net:[1]", "pod-b"))
+		require.True(t, owners.observe("net:[1]", "pod-a"))
+		owners.post()
+	}
+
+	// The owner has left the namespace
+	owners.pre()
+	require.False(t, owners.observe("net:[1]", "pod-b"))
+	owners.post()
+
+	owners.pre()
+	require.True(t, owners.observe("net:[1]", "pod-b"))
+	owners.post()
+}
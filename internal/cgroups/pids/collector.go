@@ -0,0 +1,154 @@
+package pids
+
+import (
+	"context"
+	"fmt"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/pkg/math"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/mo"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+)
+
+type Collector struct {
+	races *cgroups.RaceController
+}
+
+var _ cgroups.Collector = &Collector{}
+
+func NewCollector(races *cgroups.RaceController) *Collector {
+	return &Collector{races: races}
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- processesMetric
+	descs <- threadsMetric
+
+	descs <- currentMetric
+	descs <- limitMetric
+	descs <- limitHitsMetric
+}
+
+func (c *Collector) Pre() {
+}
+
+func (c *Collector) Post(ctx context.Context) {
+}
+
+func (c *Collector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	// Root group represents kernel threads, so it's all about its own processes
+	processes, exists, err := group.AllPIDs(exclude)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	threads, exists, err := group.AllThreads(exclude)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	usage := Usage{
+		processes: int64(len(processes)),
+		threads:   int64(len(threads)),
+	}
+
+	// Root cgroup has no pids controller interface files
+	if !group.IsRoot() {
+		exists, err := c.collectController(group, exclude, &usage)
+		if err != nil || !exists {
+			return exists, err
+		}
+	}
+
+	c.record(ctx, service, usage, metrics)
+	return true, nil
+}
+
+func (c *Collector) collectController(group *cgroups.Group, exclude []string, usage *Usage) (bool, error) {
+	// pids controller may be not enabled for the group
+	if enabled, err := group.HasProperty("pids.current"); err != nil || !enabled {
+		return true, err
+	}
+
+	current, exists, err := cgroupsutil.ReadValue(group, "pids.current")
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	for _, name := range exclude {
+		child := group.Child(name)
+
+		childCurrent, exists, err := cgroupsutil.ReadValue(child, "pids.current")
+		if err != nil {
+			return false, err
+		} else if !exists {
+			return false, c.races.Check(group, fmt.Errorf("%q is missing, but is expected to exist", child.Path()))
+		}
+
+		current = math.MaxInt64(0, current-childCurrent)
+	}
+
+	limit, exists, err := cgroupsutil.ReadLimit(group, "pids.max")
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	events, exists, err := cgroupsutil.ReadStat(group, "pids.events")
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	limitHits, err := events.Get("max")
+	if err != nil {
+		return true, err
+	}
+
+	usage.controller = mo.Some(controllerUsage{
+		current:   current,
+		limit:     limit,
+		limitHits: limitHits,
+	})
+
+	return true, nil
+}
+
+func (c *Collector) record(ctx context.Context, service string, usage Usage, metrics chan<- prometheus.Metric) {
+	metrics <- prometheus.MustNewConstMetric(processesMetric, prometheus.GaugeValue, float64(usage.processes), service)
+	metrics <- prometheus.MustNewConstMetric(threadsMetric, prometheus.GaugeValue, float64(usage.threads), service)
+
+	controller, ok := usage.controller.Get()
+	if !ok {
+		logging.L(ctx).Debugf("* %s: pids: processes=%d, threads=%d", service, usage.processes, usage.threads)
+		return
+	}
+
+	limit := "max"
+	if value, ok := controller.limit.Get(); ok {
+		limit = fmt.Sprint(value)
+		metrics <- prometheus.MustNewConstMetric(limitMetric, prometheus.GaugeValue, float64(value), service)
+	}
+
+	logging.L(ctx).Debugf(
+		"* %s: pids: processes=%d, threads=%d, current=%d, limit=%s, limit hits=%d",
+		service, usage.processes, usage.threads, controller.current, limit, controller.limitHits)
+
+	metrics <- prometheus.MustNewConstMetric(currentMetric, prometheus.GaugeValue, float64(controller.current), service)
+	metrics <- prometheus.MustNewConstMetric(limitHitsMetric, prometheus.CounterValue, float64(controller.limitHits), service)
+}
+
+type Usage struct {
+	processes  int64
+	threads    int64
+	controller mo.Option[controllerUsage]
+}
+
+type controllerUsage struct {
+	current   int64
+	limit     mo.Option[int64]
+	limitHits int64
+}
@@ -0,0 +1,15 @@
+package pids
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var metricBuilder = metrics.MakeDescBuilder("services_pids").WithLabels("service")
+
+var processesMetric = cgroups.SumAggregated(metricBuilder.Build("processes", "Number of processes in the service.", nil))
+var threadsMetric = cgroups.SumAggregated(metricBuilder.Build("threads", "Number of threads in the service.", nil))
+
+var currentMetric = cgroups.SumAggregated(metricBuilder.Build("current", "Number of tasks accounted by pids controller for the service.", nil))
+var limitMetric = metricBuilder.Build("limit", "Maximum number of tasks the service may have (unlimited one isn't reported).", nil)
+var limitHitsMetric = cgroups.SumAggregated(metricBuilder.Build("limit_hits", "Number of fork() failures due to the service's tasks limit.", nil))
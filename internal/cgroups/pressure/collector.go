@@ -0,0 +1,102 @@
+package pressure
+
+import (
+	"context"
+	"os"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+)
+
+type Collector struct {
+	enabled bool
+}
+
+var _ cgroups.Collector = &Collector{}
+
+func NewCollector() *Collector {
+	// Pressure files are missing when the kernel is booted with psi=0. Legacy hierarchy has no pressure files at all.
+	_, err := os.Stat("/proc/pressure")
+
+	return &Collector{
+		enabled: err == nil && cgroups.CurrentHierarchy().Version() != cgroups.Legacy,
+	}
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- someMetric
+	descs <- fullMetric
+}
+
+func (c *Collector) Pre() {
+}
+
+func (c *Collector) Post(ctx context.Context) {
+}
+
+// Stall time isn't additive (stalls of child groups overlap), so unlike CPU and I/O usage it can't be calculated for
+// the root and "total excluding" groups by subtracting their children. Pressure of such groups includes the pressure
+// of their excluded children, so it's not reported at all.
+func (c *Collector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	if !c.enabled || group.IsRoot() || len(exclude) != 0 {
+		return true, nil
+	}
+
+	usage, exists, err := c.collect(group)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	c.record(ctx, service, usage, metrics)
+	return true, nil
+}
+
+func (c *Collector) collect(group *cgroups.Group) (Usage, bool, error) {
+	var usage Usage
+
+	for _, resource := range usage.resources() {
+		stat, exists, err := readPressure(group, resource.name)
+		if err != nil || !exists {
+			return Usage{}, exists, err
+		}
+		*resource.stat = stat
+	}
+
+	return usage, true, nil
+}
+
+func (c *Collector) record(ctx context.Context, service string, usage Usage, metrics chan<- prometheus.Metric) {
+	const usec = 1_000_000
+
+	for _, resource := range usage.resources() {
+		some := float64(resource.stat.some) / usec
+		full := float64(resource.stat.full) / usec
+		logging.L(ctx).Debugf("* %s: %s pressure: some=%.1fs, full=%.1fs", service, resource.name, some, full)
+
+		metrics <- prometheus.MustNewConstMetric(someMetric, prometheus.CounterValue, some, service, resource.name)
+		metrics <- prometheus.MustNewConstMetric(fullMetric, prometheus.CounterValue, full, service, resource.name)
+	}
+}
+
+type Usage struct {
+	cpu    pressureStat
+	memory pressureStat
+	io     pressureStat
+}
+
+type resourceUsage struct {
+	name string
+	stat *pressureStat
+}
+
+func (u *Usage) resources() []resourceUsage {
+	return []resourceUsage{
+		{"cpu", &u.cpu},
+		{"memory", &u.memory},
+		{"io", &u.io},
+	}
+}
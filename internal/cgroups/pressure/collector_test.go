@@ -0,0 +1,61 @@
+package pressure
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+)
+
+func TestCollectorSkipsNetUsageGroups(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zap.NewNop().Sugar())
+	root := t.TempDir()
+
+	const pressure = "some avg10=0.00 avg60=0.00 avg300=0.00 total=1000000\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=500000\n"
+
+	for _, group := range []string{"", "system.slice", "user.slice"} {
+		groupPath := path.Join(root, group)
+		require.NoError(t, os.MkdirAll(groupPath, 0o755))
+		require.NoError(t, os.WriteFile(path.Join(groupPath, "cgroup.controllers"), []byte("cpu io memory"), 0o644))
+
+		for _, resource := range []string{"cpu", "memory", "io"} {
+			require.NoError(t, os.WriteFile(path.Join(groupPath, resource+".pressure"), []byte(pressure), 0o644))
+		}
+	}
+
+	_, err := cgroups.InitUnified(root)
+	require.NoError(t, err)
+
+	collector := &Collector{enabled: true}
+	rootGroup := cgroups.NewGroup("/", nil)
+
+	for _, testCase := range []struct {
+		group    *cgroups.Group
+		exclude  []string
+		reported bool
+	}{
+		{rootGroup, nil, false},
+		{rootGroup.Child("user.slice"), []string{"user-1000.slice"}, false},
+		{rootGroup.Child("system.slice"), nil, true},
+	} {
+		metrics := make(chan prometheus.Metric, 10)
+
+		exists, err := collector.Collect(ctx, "service", testCase.group, testCase.exclude, metrics)
+		require.NoError(t, err)
+		require.True(t, exists)
+		close(metrics)
+
+		if testCase.reported {
+			require.Len(t, metrics, 6, testCase.group.Name)
+		} else {
+			require.Empty(t, metrics, testCase.group.Name)
+		}
+	}
+}
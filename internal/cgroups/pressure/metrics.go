@@ -0,0 +1,13 @@
+package pressure
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var metricBuilder = metrics.MakeDescBuilder("services_pressure").WithLabels("service")
+
+var someMetric = metricBuilder.Build(
+	"some", "Time during which at least some tasks of the service were stalled on the resource (not reported for the root and services with excluded children).", []string{"resource"})
+
+var fullMetric = metricBuilder.Build(
+	"full", "Time during which all non-idle tasks of the service were stalled on the resource simultaneously (not reported for the root and services with excluded children).", []string{"resource"})
@@ -0,0 +1,82 @@
+package pressure
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+type pressureStat struct {
+	some int64
+	full int64
+}
+
+func readPressure(group *cgroups.Group, resource string) (pressureStat, bool, error) {
+	var stat pressureStat
+	exists, err := group.ReadProperty(resource+".pressure", func(file io.Reader) (err error) {
+		stat, err = parsePressure(file)
+		return
+	})
+	return stat, exists, err
+}
+
+// https://docs.kernel.org/accounting/psi.html
+func parsePressure(reader io.Reader) (pressureStat, error) {
+	var (
+		stat     pressureStat
+		someRead bool
+		fullRead bool
+	)
+
+	if err := util.ParseFile(reader, func(line string) error {
+		tokens := strings.Split(line, " ")
+
+		var total *int64
+		switch tokens[0] {
+		case "some":
+			if someRead {
+				return fmt.Errorf("Got a duplicated %q line", tokens[0])
+			}
+			total, someRead = &stat.some, true
+		case "full":
+			if fullRead {
+				return fmt.Errorf("Got a duplicated %q line", tokens[0])
+			}
+			total, fullRead = &stat.full, true
+		default:
+			return fmt.Errorf("Got an unexpected pressure line: %q", line)
+		}
+
+		for _, token := range tokens[1:] {
+			key, value, ok := strings.Cut(token, "=")
+			if !ok {
+				return fmt.Errorf("Got an unexpected pressure line: %q", line)
+			} else if key != "total" {
+				continue
+			}
+
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("Got an unexpected pressure line: %q", line)
+			}
+
+			*total = parsed
+			return nil
+		}
+
+		return fmt.Errorf("Got a pressure line without total: %q", line)
+	}); err != nil {
+		return pressureStat{}, err
+	}
+
+	// "full" line is optional: old kernels don't report it for CPU pressure
+	if !someRead {
+		return pressureStat{}, fmt.Errorf("%q line is missing", "some")
+	}
+
+	return stat, nil
+}
@@ -0,0 +1,29 @@
+package pressure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePressure(t *testing.T) {
+	stat, err := parsePressure(strings.NewReader(heredoc.Doc(`
+		some avg10=0.12 avg60=0.05 avg300=0.01 total=3187945
+		full avg10=0.00 avg60=0.00 avg300=0.00 total=1519183
+	`)))
+	require.NoError(t, err)
+	require.Equal(t, pressureStat{some: 3187945, full: 1519183}, stat)
+
+	stat, err = parsePressure(strings.NewReader(heredoc.Doc(`
+		some avg10=0.00 avg60=0.00 avg300=0.00 total=42
+	`)))
+	require.NoError(t, err)
+	require.Equal(t, pressureStat{some: 42}, stat)
+
+	_, err = parsePressure(strings.NewReader(heredoc.Doc(`
+		some avg10=0.00 avg60=0.00 avg300=0.00
+	`)))
+	require.Error(t, err)
+}
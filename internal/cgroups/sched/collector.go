@@ -0,0 +1,108 @@
+package sched
+
+import (
+	"context"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+	"github.com/KonishchevDmitry/server-metrics/internal/procfs"
+)
+
+// Collector collects scheduler statistics of the service threads. The statistics is per-thread, so it's collected
+// for each thread separately.
+type Collector struct {
+	counters *cgroupsutil.TaskCounters
+}
+
+var _ cgroups.Collector = &Collector{}
+
+func NewCollector() *Collector {
+	return &Collector{
+		counters: cgroupsutil.NewTaskCounters("sched"),
+	}
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- runTimeMetric
+	descs <- waitTimeMetric
+	descs <- timeslicesMetric
+
+	descs <- voluntarySwitchesMetric
+	descs <- nonvoluntarySwitchesMetric
+}
+
+func (c *Collector) Pre() {
+	c.counters.Pre()
+}
+
+func (c *Collector) Post(ctx context.Context) {
+	c.counters.Post(ctx)
+}
+
+func (c *Collector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	tids, exists, err := group.AllThreads(exclude)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	threads := make(map[int][]uint64, len(tids))
+	taskErrors := cgroupsutil.NewTaskErrors("sched")
+
+	for _, tid := range tids {
+		stat, exists, err := readThreadStat(tid)
+		if err != nil {
+			taskErrors.Failed(ctx, tid, err)
+		} else if exists {
+			taskErrors.Read()
+			threads[tid] = stat.toCounters()
+		}
+	}
+
+	if err := taskErrors.Err(); err != nil {
+		return true, err
+	}
+
+	usage := fromCounters(c.counters.Update(group.Name, threads))
+	c.record(ctx, service, usage, metrics)
+
+	return true, nil
+}
+
+func readThreadStat(tid int) (schedStat, bool, error) {
+	var stat schedStat
+
+	// Thread IDs aren't listed in /proc, but are accessible as /proc/<tid>
+	data, exists, err := procfs.ReadFile(tid, "schedstat")
+	if err != nil || !exists {
+		return stat, exists, err
+	} else if err := parseSchedStat(data, &stat); err != nil {
+		return stat, false, err
+	}
+
+	data, exists, err = procfs.ReadFile(tid, "status")
+	if err != nil || !exists {
+		return stat, exists, err
+	} else if err := parseContextSwitches(data, &stat); err != nil {
+		return stat, false, err
+	}
+
+	return stat, true, nil
+}
+
+func (c *Collector) record(ctx context.Context, service string, usage schedStat, metrics chan<- prometheus.Metric) {
+	logging.L(ctx).Debugf(
+		"* %s: sched: run=%s, wait=%s, timeslices=%d, voluntary switches=%d, nonvoluntary switches=%d",
+		service, usage.runTime, usage.waitTime, usage.timeslices, usage.voluntarySwitches, usage.nonvoluntarySwitches)
+
+	metrics <- prometheus.MustNewConstMetric(runTimeMetric, prometheus.CounterValue, usage.runTime.Seconds(), service)
+	metrics <- prometheus.MustNewConstMetric(waitTimeMetric, prometheus.CounterValue, usage.waitTime.Seconds(), service)
+	metrics <- prometheus.MustNewConstMetric(timeslicesMetric, prometheus.CounterValue, float64(usage.timeslices), service)
+
+	metrics <- prometheus.MustNewConstMetric(voluntarySwitchesMetric, prometheus.CounterValue, float64(usage.voluntarySwitches), service)
+	metrics <- prometheus.MustNewConstMetric(nonvoluntarySwitchesMetric, prometheus.CounterValue, float64(usage.nonvoluntarySwitches), service)
+}
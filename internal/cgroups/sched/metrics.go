@@ -0,0 +1,15 @@
+package sched
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var metricBuilder = metrics.MakeDescBuilder("services_sched").WithLabels("service")
+
+var runTimeMetric = cgroups.SumAggregated(metricBuilder.Build("run_time", "Time spent by the service threads on CPU.", nil))
+var waitTimeMetric = cgroups.SumAggregated(metricBuilder.Build("wait_time", "Time spent by the service threads waiting on a run queue.", nil))
+var timeslicesMetric = cgroups.SumAggregated(metricBuilder.Build("timeslices", "Number of timeslices run by the service threads.", nil))
+
+var voluntarySwitchesMetric = cgroups.SumAggregated(metricBuilder.Build("voluntary_context_switches", "Number of voluntary context switches of the service threads.", nil))
+var nonvoluntarySwitchesMetric = cgroups.SumAggregated(metricBuilder.Build("nonvoluntary_context_switches", "Number of nonvoluntary context switches of the service threads.", nil))
@@ -0,0 +1,103 @@
+package sched
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+type schedStat struct {
+	runTime    time.Duration
+	waitTime   time.Duration
+	timeslices uint64
+
+	voluntarySwitches    uint64
+	nonvoluntarySwitches uint64
+}
+
+func (s *schedStat) toCounters() []uint64 {
+	return []uint64{
+		uint64(s.runTime), uint64(s.waitTime), s.timeslices,
+		s.voluntarySwitches, s.nonvoluntarySwitches,
+	}
+}
+
+func fromCounters(counters []uint64) schedStat {
+	if counters == nil {
+		return schedStat{}
+	}
+	return schedStat{
+		runTime:    time.Duration(counters[0]),
+		waitTime:   time.Duration(counters[1]),
+		timeslices: counters[2],
+
+		voluntarySwitches:    counters[3],
+		nonvoluntarySwitches: counters[4],
+	}
+}
+
+// https://docs.kernel.org/scheduler/sched-stats.html#proc-pid-schedstat
+func parseSchedStat(data []byte, stat *schedStat) error {
+	fields := strings.Fields(string(data))
+	if len(fields) != 3 {
+		return fmt.Errorf("Got an unexpected schedstat: %q", string(data))
+	}
+
+	var values [3]uint64
+	for index, field := range fields {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Got an unexpected schedstat: %q", string(data))
+		}
+		values[index] = value
+	}
+
+	stat.runTime = time.Duration(values[0])
+	stat.waitTime = time.Duration(values[1])
+	stat.timeslices = values[2]
+
+	return nil
+}
+
+// https://man7.org/linux/man-pages/man5/proc_pid_status.5.html
+func parseContextSwitches(data []byte, stat *schedStat) error {
+	fields := map[string]*uint64{
+		"voluntary_ctxt_switches":    &stat.voluntarySwitches,
+		"nonvoluntary_ctxt_switches": &stat.nonvoluntarySwitches,
+	}
+	found := 0
+
+	if err := util.ParseFile(bytes.NewReader(data), func(line string) error {
+		name, valueString, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			return nil
+		}
+
+		value, err := strconv.ParseUint(strings.TrimSpace(valueString), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		*field = value
+		found++
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if found != len(fields) {
+		return fmt.Errorf("Context switch counters are missing")
+	}
+
+	return nil
+}
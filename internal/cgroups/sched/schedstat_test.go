@@ -0,0 +1,41 @@
+package sched
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedStat(t *testing.T) {
+	var stat schedStat
+	require.NoError(t, parseSchedStat([]byte("1813454887 204611305 2317\n"), &stat))
+	require.Equal(t, schedStat{
+		runTime:    1813454887 * time.Nanosecond,
+		waitTime:   204611305 * time.Nanosecond,
+		timeslices: 2317,
+	}, stat)
+
+	require.Error(t, parseSchedStat([]byte("1813454887 204611305\n"), &stat))
+}
+
+func TestParseContextSwitches(t *testing.T) {
+	data := []byte(
+		"Name:\tsshd\n" +
+			"Umask:\t0022\n" +
+			"State:\tS (sleeping)\n" +
+			"Threads:\t1\n" +
+			"Cpus_allowed_list:\t0-7\n" +
+			"voluntary_ctxt_switches:\t1540\n" +
+			"nonvoluntary_ctxt_switches:\t12\n",
+	)
+
+	var stat schedStat
+	require.NoError(t, parseContextSwitches(data, &stat))
+	require.Equal(t, schedStat{
+		voluntarySwitches:    1540,
+		nonvoluntarySwitches: 12,
+	}, stat)
+
+	require.Error(t, parseContextSwitches([]byte("Name:\tsshd\n"), &stat))
+}
@@ -0,0 +1,147 @@
+package smaps
+
+import (
+	"context"
+	"time"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+	"github.com/KonishchevDmitry/server-metrics/internal/procfs"
+)
+
+// Collector collects proportional memory usage of the service processes. Reading smaps_rollup requires walking all
+// process page tables, which is expensive on big hosts, so the collected values are cached for the specified interval.
+type Collector struct {
+	interval time.Duration
+	groups   map[string]*groupState
+}
+
+type groupState struct {
+	usage       memoryUsage
+	collectedAt time.Time
+	collected   bool
+}
+
+var _ cgroups.Collector = &Collector{}
+
+func NewCollector(interval time.Duration) *Collector {
+	return &Collector{
+		interval: interval,
+		groups:   make(map[string]*groupState),
+	}
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- pssMetric
+	descs <- pssBreakdownMetric
+	descs <- privateMetric
+	descs <- ussMetric
+}
+
+func (c *Collector) Pre() {
+	for _, state := range c.groups {
+		state.collected = false
+	}
+}
+
+func (c *Collector) Post(ctx context.Context) {
+	for group, state := range c.groups {
+		if !state.collected {
+			logging.L(ctx).Debugf("smaps: %q group hasn't been collected. Dropping its state.", group)
+			delete(c.groups, group)
+		}
+	}
+}
+
+func (c *Collector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	if state, ok := c.groups[group.Name]; ok && time.Since(state.collectedAt) < c.interval {
+		state.collected = true
+		c.record(ctx, service, state.usage, metrics)
+		return true, nil
+	}
+
+	pids, exists, err := group.AllPIDs(exclude)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	usage := memoryUsage{pssBreakdown: true}
+
+	taskErrors := cgroupsutil.NewTaskErrors("smaps")
+
+	for _, pid := range pids {
+		processUsage, exists, err := readRollup(pid)
+		if err != nil {
+			taskErrors.Failed(ctx, pid, err)
+			continue
+		} else if !exists {
+			continue
+		}
+		taskErrors.Read()
+
+		usage.add(&processUsage)
+		usage.pssBreakdown = usage.pssBreakdown && processUsage.pssBreakdown
+	}
+
+	if err := taskErrors.Err(); err != nil {
+		return true, err
+	}
+
+	c.groups[group.Name] = &groupState{
+		usage:       usage,
+		collectedAt: time.Now(),
+		collected:   true,
+	}
+	c.record(ctx, service, usage, metrics)
+
+	return true, nil
+}
+
+// Returns false for missing processes and the ones without address space (kernel threads and zombies)
+func readRollup(pid int) (memoryUsage, bool, error) {
+	data, exists, err := procfs.ReadFile(pid, "smaps_rollup")
+	if err != nil || !exists {
+		return memoryUsage{}, exists, err
+	}
+	return parseRollup(data)
+}
+
+func (c *Collector) record(ctx context.Context, service string, usage memoryUsage, metrics chan<- prometheus.Metric) {
+	logging.L(ctx).Debugf(
+		"* %s: smaps: pss=%d (anon=%d, file=%d, shmem=%d), private=%d+%d+%d",
+		service, usage.pss, usage.pssAnon, usage.pssFile, usage.pssShmem,
+		usage.privateClean, usage.privateDirty, usage.privateHugetlb)
+
+	metrics <- prometheus.MustNewConstMetric(pssMetric, prometheus.GaugeValue, float64(usage.pss), service)
+
+	if usage.pssBreakdown {
+		for _, stat := range []struct {
+			name  string
+			value uint64
+		}{
+			{"anon", usage.pssAnon},
+			{"file", usage.pssFile},
+			{"shmem", usage.pssShmem},
+		} {
+			metrics <- prometheus.MustNewConstMetric(pssBreakdownMetric, prometheus.GaugeValue, float64(stat.value), service, stat.name)
+		}
+	}
+
+	for _, stat := range []struct {
+		name  string
+		value uint64
+	}{
+		{"clean", usage.privateClean},
+		{"dirty", usage.privateDirty},
+		{"hugetlb", usage.privateHugetlb},
+	} {
+		metrics <- prometheus.MustNewConstMetric(privateMetric, prometheus.GaugeValue, float64(stat.value), service, stat.name)
+	}
+
+	metrics <- prometheus.MustNewConstMetric(ussMetric, prometheus.GaugeValue, float64(usage.uss()), service)
+}
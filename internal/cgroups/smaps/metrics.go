@@ -0,0 +1,20 @@
+package smaps
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var metricBuilder = metrics.MakeDescBuilder("services_smaps").WithLabels("service")
+
+var pssMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"pss", "Proportional set size of the service processes (see smaps_rollup).", nil))
+
+var pssBreakdownMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"pss_breakdown", "Proportional set size of the service processes by memory type (see smaps_rollup).", []string{"type"}))
+
+var privateMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"private", "Memory used exclusively by the service processes (see smaps_rollup).", []string{"type"}))
+
+var ussMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"uss", "Unique set size of the service processes: the memory which would be freed if they exited.", nil))
@@ -0,0 +1,107 @@
+package smaps
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+type memoryUsage struct {
+	pss      uint64
+	pssAnon  uint64
+	pssFile  uint64
+	pssShmem uint64
+
+	privateClean   uint64
+	privateDirty   uint64
+	privateHugetlb uint64
+
+	// Pss_Anon, Pss_File and Pss_Shmem are available only since Linux 5.9
+	pssBreakdown bool
+}
+
+func (u *memoryUsage) add(other *memoryUsage) {
+	u.pss += other.pss
+	u.pssAnon += other.pssAnon
+	u.pssFile += other.pssFile
+	u.pssShmem += other.pssShmem
+
+	u.privateClean += other.privateClean
+	u.privateDirty += other.privateDirty
+	u.privateHugetlb += other.privateHugetlb
+}
+
+func (u *memoryUsage) uss() uint64 {
+	return u.privateClean + u.privateDirty + u.privateHugetlb
+}
+
+// Parses /proc/$pid/smaps_rollup. Returns false if the process has no address space (kernel threads and zombies).
+//
+// https://www.kernel.org/doc/html/latest/filesystems/proc.html#proc-pid-smaps-rollup
+func parseRollup(data []byte) (memoryUsage, bool, error) {
+	var usage memoryUsage
+	if len(data) == 0 {
+		return usage, false, nil
+	}
+
+	fields := map[string]*uint64{
+		"Pss":             &usage.pss,
+		"Private_Clean":   &usage.privateClean,
+		"Private_Dirty":   &usage.privateDirty,
+		"Private_Hugetlb": &usage.privateHugetlb,
+	}
+	breakdownFields := map[string]*uint64{
+		"Pss_Anon":  &usage.pssAnon,
+		"Pss_File":  &usage.pssFile,
+		"Pss_Shmem": &usage.pssShmem,
+	}
+	found, foundBreakdown := 0, 0
+
+	header := true
+	if err := util.ParseFile(bytes.NewReader(data), func(line string) error {
+		// The first line is a pseudo mapping header
+		if header {
+			header = false
+			return nil
+		}
+
+		name, valueString, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		field, ok := fields[name]
+		if ok {
+			found++
+		} else if field, ok = breakdownFields[name]; ok {
+			foundBreakdown++
+		} else {
+			return nil
+		}
+
+		valueString, ok = strings.CutSuffix(strings.TrimSpace(valueString), " kB")
+		if !ok {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		value, err := strconv.ParseUint(valueString, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Got an unexpected line: %q", line)
+		}
+
+		*field = value * 1024
+		return nil
+	}); err != nil {
+		return memoryUsage{}, false, err
+	}
+
+	if found != len(fields) || (foundBreakdown != 0 && foundBreakdown != len(breakdownFields)) {
+		return memoryUsage{}, false, fmt.Errorf("Some of the fields are missing: %q", string(data))
+	}
+	usage.pssBreakdown = foundBreakdown != 0
+
+	return usage, true, nil
+}
@@ -0,0 +1,63 @@
+package smaps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRollup(t *testing.T) {
+	data := "" +
+		"5589872b8000-7fffd3107000 ---p 00000000 00:00 0                          [rollup]\n" +
+		"Rss:                1388 kB\n" +
+		"Pss:                 361 kB\n" +
+		"Pss_Dirty:           104 kB\n" +
+		"Pss_Anon:            104 kB\n" +
+		"Pss_File:            257 kB\n" +
+		"Pss_Shmem:             0 kB\n" +
+		"Shared_Clean:       1244 kB\n" +
+		"Shared_Dirty:          0 kB\n" +
+		"Private_Clean:        40 kB\n" +
+		"Private_Dirty:       104 kB\n" +
+		"Referenced:         1388 kB\n" +
+		"Anonymous:           104 kB\n" +
+		"Shared_Hugetlb:        0 kB\n" +
+		"Private_Hugetlb:       2 kB\n" +
+		"Swap:                  0 kB\n"
+
+	usage, ok, err := parseRollup([]byte(data))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, memoryUsage{
+		pss:            361 * 1024,
+		pssAnon:        104 * 1024,
+		pssFile:        257 * 1024,
+		pssShmem:       0,
+		privateClean:   40 * 1024,
+		privateDirty:   104 * 1024,
+		privateHugetlb: 2 * 1024,
+		pssBreakdown:   true,
+	}, usage)
+	require.Equal(t, uint64(146*1024), usage.uss())
+}
+
+func TestParseRollupWithoutBreakdown(t *testing.T) {
+	data := "" +
+		"5589872b8000-7fffd3107000 ---p 00000000 00:00 0                          [rollup]\n" +
+		"Pss:                 361 kB\n" +
+		"Private_Clean:        40 kB\n" +
+		"Private_Dirty:       104 kB\n" +
+		"Private_Hugetlb:       0 kB\n"
+
+	usage, ok, err := parseRollup([]byte(data))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.False(t, usage.pssBreakdown)
+	require.Equal(t, uint64(361*1024), usage.pss)
+}
+
+func TestParseRollupEmpty(t *testing.T) {
+	_, ok, err := parseRollup(nil)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
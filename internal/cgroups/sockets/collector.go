@@ -0,0 +1,216 @@
+package sockets
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/procfs"
+)
+
+// Collector maps sockets of the service's processes (found via /proc/$pid/fd) to /proc/net/{tcp,udp} entries of the
+// processes' network namespaces.
+type Collector struct {
+	ephemeralPorts portRange
+	tables         map[string]*socketTable // Socket tables loaded during the current collection
+}
+
+var _ cgroups.Collector = &Collector{}
+
+func NewCollector() (*Collector, error) {
+	ephemeralPorts, err := readEphemeralPorts()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get ephemeral ports range: %w", err)
+	}
+
+	return &Collector{
+		ephemeralPorts: ephemeralPorts,
+		tables:         make(map[string]*socketTable),
+	}, nil
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- listeningSocketMetric
+	descs <- connectionsMetric
+}
+
+func (c *Collector) Pre() {
+	clear(c.tables)
+}
+
+func (c *Collector) Post(ctx context.Context) {
+}
+
+func (c *Collector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	pids, exists, err := group.AllPIDs(exclude)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	inodes := make(map[*socketTable]map[uint64]struct{})
+
+	for _, pid := range pids {
+		table, exists, err := c.getTable(pid)
+		if err != nil {
+			return true, err
+		} else if !exists {
+			continue
+		}
+
+		tableInodes, ok := inodes[table]
+		if !ok {
+			tableInodes = make(map[uint64]struct{})
+			inodes[table] = tableInodes
+		}
+
+		if err := getSocketInodes(pid, tableInodes); err != nil {
+			return true, err
+		}
+	}
+
+	var (
+		listening   = make(map[listeningSocket]struct{})
+		connections = make(map[connectionKey]int)
+	)
+
+	for table, tableInodes := range inodes {
+		var ports []netip.AddrPort
+
+		for inode := range tableInodes {
+			socket, ok := table.sockets[inode]
+			if !ok {
+				// Not an IP socket or it has been created after the table loading
+				continue
+			}
+
+			if socket.isListening(c.ephemeralPorts) {
+				listening[listeningSocket{protocol: socket.protocol, address: socket.local}] = struct{}{}
+				if socket.protocol == "tcp" {
+					ports = append(ports, socket.local)
+				}
+			} else if socket.isConnection() {
+				connections[connectionKey{protocol: socket.protocol, state: socket.state}]++
+			}
+		}
+
+		// TIME-WAIT sockets are orphaned, so we can only attribute the ones which belong to the service's listening
+		// ports.
+		for _, socket := range table.timeWait {
+			for _, port := range ports {
+				if socket.local.Port() == port.Port() && (port.Addr().IsUnspecified() || socket.local.Addr() == port.Addr()) {
+					connections[connectionKey{protocol: socket.protocol, state: socket.state}]++
+					break
+				}
+			}
+		}
+	}
+
+	c.record(ctx, service, listening, connections, metrics)
+	return true, nil
+}
+
+func (c *Collector) getTable(pid int) (*socketTable, bool, error) {
+	namespace, exists, err := procfs.Readlink(pid, "ns/net")
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	if table, ok := c.tables[namespace]; ok {
+		return table, true, nil
+	}
+
+	table := &socketTable{sockets: make(map[uint64]socket)}
+
+	for _, file := range []struct {
+		name     string
+		protocol string
+	}{
+		{"tcp", "tcp"},
+		{"tcp6", "tcp"},
+		{"udp", "udp"},
+		{"udp6", "udp"},
+	} {
+		data, exists, err := procfs.ReadFile(pid, "net/"+file.name)
+		if err != nil {
+			return nil, false, fmt.Errorf("Failed to read %s sockets: %w", file.name, err)
+		} else if !exists {
+			return nil, false, nil
+		}
+
+		sockets, err := parseSockets(file.protocol, data)
+		if err != nil {
+			return nil, false, fmt.Errorf("Failed to read %s sockets: %w", file.name, err)
+		}
+
+		for _, socket := range sockets {
+			if socket.inode != 0 {
+				table.sockets[socket.inode] = socket
+			} else if socket.state == stateTimeWait {
+				table.timeWait = append(table.timeWait, socket)
+			}
+		}
+	}
+
+	c.tables[namespace] = table
+	return table, true, nil
+}
+
+func getSocketInodes(pid int, inodes map[uint64]struct{}) error {
+	fds, exists, err := procfs.ReadDir(pid, "fd")
+	if err != nil || !exists {
+		return err
+	}
+
+	for _, fd := range fds {
+		target, exists, err := procfs.Readlink(pid, "fd/"+fd.Name())
+		if err != nil {
+			return err
+		} else if !exists {
+			continue
+		}
+
+		if inode, ok := parseSocketInode(target); ok {
+			inodes[inode] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+func (c *Collector) record(
+	ctx context.Context, service string, listening map[listeningSocket]struct{}, connections map[connectionKey]int,
+	metrics chan<- prometheus.Metric,
+) {
+	for socket := range listening {
+		address, port := socket.address.Addr().String(), strconv.Itoa(int(socket.address.Port()))
+		logging.L(ctx).Debugf("* %s: listening on %s/%s", service, socket.address, socket.protocol)
+		metrics <- prometheus.MustNewConstMetric(listeningSocketMetric, prometheus.GaugeValue, 1, service, socket.protocol, address, port)
+	}
+
+	for key, count := range connections {
+		logging.L(ctx).Debugf("* %s: %s %s connections: %d", service, key.protocol, key.state, count)
+		metrics <- prometheus.MustNewConstMetric(connectionsMetric, prometheus.GaugeValue, float64(count), service, key.protocol, key.state.String())
+	}
+}
+
+type socketTable struct {
+	sockets  map[uint64]socket
+	timeWait []socket
+}
+
+type listeningSocket struct {
+	protocol string
+	address  netip.AddrPort
+}
+
+type connectionKey struct {
+	protocol string
+	state    socketState
+}
@@ -0,0 +1,14 @@
+package sockets
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var metricBuilder = metrics.MakeDescBuilder("services").WithLabels("service")
+
+var listeningSocketMetric = cgroups.MaxAggregated(metricBuilder.Build(
+	"listening_socket_info", "Sockets the service is listening on.", []string{"protocol", "address", "port"}))
+
+var connectionsMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"connections", "Number of the service's connections by state.", []string{"protocol", "state"}))
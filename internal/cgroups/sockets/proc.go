@@ -0,0 +1,221 @@
+package sockets
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/util"
+)
+
+type socket struct {
+	protocol string
+	local    netip.AddrPort
+	remote   netip.AddrPort
+	state    socketState
+	inode    uint64
+}
+
+type socketState uint8
+
+// See include/net/tcp_states.h
+const (
+	stateEstablished socketState = 0x01
+	stateTimeWait    socketState = 0x06
+	stateClose       socketState = 0x07
+	stateListen      socketState = 0x0A
+)
+
+var stateNames = map[socketState]string{
+	0x01: "established",
+	0x02: "syn-sent",
+	0x03: "syn-recv",
+	0x04: "fin-wait1",
+	0x05: "fin-wait2",
+	0x06: "time-wait",
+	0x07: "close",
+	0x08: "close-wait",
+	0x09: "last-ack",
+	0x0A: "listen",
+	0x0B: "closing",
+	0x0C: "new-syn-recv",
+}
+
+func (s socketState) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown-%d", s)
+}
+
+// Checks whether the socket accepts new connections/datagrams. Unconnected UDP sockets are also used by clients (DNS
+// resolvers for example), so they are considered listening only when bound to a non-ephemeral port.
+func (s *socket) isListening(ephemeralPorts portRange) bool {
+	switch s.protocol {
+	case "tcp":
+		return s.state == stateListen
+	case "udp":
+		return s.state == stateClose && s.remote.Port() == 0 && !ephemeralPorts.contains(s.local.Port())
+	default:
+		return false
+	}
+}
+
+// Checks whether the socket is a connection. Unconnected UDP sockets which aren't listening are client sockets (DNS
+// resolvers and NTP clients for example) which aren't bound to any peer, so they aren't considered connections.
+func (s *socket) isConnection() bool {
+	switch s.protocol {
+	case "tcp":
+		return s.state != stateListen
+	case "udp":
+		return s.state == stateEstablished
+	default:
+		return false
+	}
+}
+
+type portRange struct {
+	first uint16
+	last  uint16
+}
+
+func (r portRange) contains(port uint16) bool {
+	return port >= r.first && port <= r.last
+}
+
+// Reads the range of ports which are used for automatic binding. The range is configured per network namespace, but
+// there is no way to read it for other namespaces via procfs, so the host one is used for all namespaces.
+func readEphemeralPorts() (portRange, error) {
+	const path = "/proc/sys/net/ipv4/ip_local_port_range"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return portRange{}, err
+	}
+
+	ports, err := parsePortRange(string(data))
+	if err != nil {
+		return portRange{}, fmt.Errorf("Failed to parse %s: %w", path, err)
+	}
+
+	return ports, nil
+}
+
+func parsePortRange(data string) (portRange, error) {
+	fields := strings.Fields(data)
+	if len(fields) != 2 {
+		return portRange{}, fmt.Errorf("Got an unexpected value: %q", data)
+	}
+
+	var ports [2]uint16
+
+	for index, field := range fields {
+		port, err := strconv.ParseUint(field, 10, 16)
+		if err != nil {
+			return portRange{}, fmt.Errorf("Got an unexpected value: %q", data)
+		}
+		ports[index] = uint16(port)
+	}
+
+	return portRange{first: ports[0], last: ports[1]}, nil
+}
+
+// Parses /proc/net/{tcp,tcp6,udp,udp6} (see https://docs.kernel.org/networking/proc_net_tcp.html)
+func parseSockets(protocol string, data []byte) ([]socket, error) {
+	var (
+		sockets []socket
+		header  = true
+	)
+
+	if err := util.ParseFile(bytes.NewReader(data), func(line string) error {
+		if header {
+			header = false
+			return nil
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			return fmt.Errorf("Got an unexpected socket line: %q", line)
+		}
+
+		local, err := parseAddress(fields[1])
+		if err != nil {
+			return fmt.Errorf("Got an unexpected socket line: %q", line)
+		}
+
+		remote, err := parseAddress(fields[2])
+		if err != nil {
+			return fmt.Errorf("Got an unexpected socket line: %q", line)
+		}
+
+		state, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil {
+			return fmt.Errorf("Got an unexpected socket line: %q", line)
+		}
+
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			return fmt.Errorf("Got an unexpected socket line: %q", line)
+		}
+
+		sockets = append(sockets, socket{
+			protocol: protocol,
+			local:    local,
+			remote:   remote,
+			state:    socketState(state),
+			inode:    inode,
+		})
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return sockets, nil
+}
+
+// Addresses are printed as a sequence of host byte order (little-endian) 32-bit words
+func parseAddress(value string) (netip.AddrPort, error) {
+	addressString, portString, ok := strings.Cut(value, ":")
+	if !ok {
+		return netip.AddrPort{}, fmt.Errorf("invalid address: %q", value)
+	}
+
+	address, err := hex.DecodeString(addressString)
+	if err != nil || len(address) != 4 && len(address) != 16 {
+		return netip.AddrPort{}, fmt.Errorf("invalid address: %q", value)
+	}
+
+	for offset := 0; offset < len(address); offset += 4 {
+		word := address[offset : offset+4]
+		word[0], word[1], word[2], word[3] = word[3], word[2], word[1], word[0]
+	}
+
+	port, err := strconv.ParseUint(portString, 16, 16)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("invalid address: %q", value)
+	}
+
+	ip, _ := netip.AddrFromSlice(address)
+	return netip.AddrPortFrom(ip.Unmap(), uint16(port)), nil
+}
+
+// Parses "socket:[$inode]" file descriptor link target
+func parseSocketInode(target string) (uint64, bool) {
+	const prefix, suffix = "socket:[", "]"
+
+	if !strings.HasPrefix(target, prefix) || !strings.HasSuffix(target, suffix) {
+		return 0, false
+	}
+
+	inode, err := strconv.ParseUint(target[len(prefix):len(target)-len(suffix)], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return inode, true
+}
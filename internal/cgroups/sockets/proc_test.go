@@ -0,0 +1,80 @@
+package sockets
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSockets(t *testing.T) {
+	sockets, err := parseSockets("tcp", []byte(heredoc.Doc(`
+		  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+		   0: 0100007F:0277 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21345 1 0000000000000000 100 0 0 10 0
+		   1: 0A00020F:0016 0100020A:D3C4 01 00000000:00000000 02:0003E0A2 00000000     0        0 48123 2 0000000000000000 20 4 29 10 -1
+	`)))
+	require.NoError(t, err)
+	require.Equal(t, []socket{{
+		protocol: "tcp",
+		local:    netip.MustParseAddrPort("127.0.0.1:631"),
+		remote:   netip.MustParseAddrPort("0.0.0.0:0"),
+		state:    stateListen,
+		inode:    21345,
+	}, {
+		protocol: "tcp",
+		local:    netip.MustParseAddrPort("15.2.0.10:22"),
+		remote:   netip.MustParseAddrPort("10.2.0.1:54212"),
+		state:    stateEstablished,
+		inode:    48123,
+	}}, sockets)
+
+	sockets, err = parseSockets("udp", []byte(heredoc.Doc(`
+		  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+		  456: 00000000000000000000000000000000:0035 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 31337 2 0000000000000000 0
+		  457: 0000000000000000FFFF00000100007F:1F90 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 31338 2 0000000000000000 0
+	`)))
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddrPort("[::]:53"), sockets[0].local)
+	require.Equal(t, netip.MustParseAddrPort("127.0.0.1:8080"), sockets[1].local)
+
+	ephemeralPorts := portRange{first: 32768, last: 60999}
+	require.True(t, sockets[0].isListening(ephemeralPorts))
+
+	sockets, err = parseSockets("udp", []byte(heredoc.Doc(`
+		  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+		  123: 00000000:A1B2 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 31339 2 0000000000000000 0
+		  124: 0A00020F:A1B3 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 31340 2 0000000000000000 0
+		  125: 0A00020F:A1B4 0100020A:007B 01 00000000:00000000 00:00000000 00000000     0        0 31341 2 0000000000000000 0
+	`)))
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddrPort("0.0.0.0:41394"), sockets[0].local)
+
+	// Unconnected client sockets are neither listening nor connections
+	for _, socket := range sockets[:2] {
+		require.False(t, socket.isListening(ephemeralPorts))
+		require.False(t, socket.isConnection())
+	}
+
+	require.Equal(t, netip.MustParseAddrPort("10.2.0.1:123"), sockets[2].remote)
+	require.False(t, sockets[2].isListening(ephemeralPorts))
+	require.True(t, sockets[2].isConnection())
+}
+
+func TestParsePortRange(t *testing.T) {
+	ports, err := parsePortRange("32768\t60999\n")
+	require.NoError(t, err)
+	require.Equal(t, portRange{first: 32768, last: 60999}, ports)
+
+	_, err = parsePortRange("32768")
+	require.Error(t, err)
+}
+
+func TestParseSocketInode(t *testing.T) {
+	inode, ok := parseSocketInode("socket:[21345]")
+	require.True(t, ok)
+	require.Equal(t, uint64(21345), inode)
+
+	_, ok = parseSocketInode("pipe:[21345]")
+	require.False(t, ok)
+}
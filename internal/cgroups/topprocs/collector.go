@@ -0,0 +1,249 @@
+package topprocs
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	logging "github.com/KonishchevDmitry/go-easy-logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tklauser/go-sysconf"
+
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups/cgroupsutil"
+	"github.com/KonishchevDmitry/server-metrics/internal/procfs"
+)
+
+// Processes which aren't in top are accounted under this name
+const otherName = "other"
+
+// Number of collections during which usage of a process name is kept after all its processes have exited. Short-lived
+// processes (cron jobs for example) may be absent during some scrapes, and dropping their usage would look like a
+// counter reset. After the grace period the name's usage is moved to "other" bucket.
+const nameGracePeriod = 10
+
+// Collector exports CPU and memory usage of top N processes of each service grouped by process name.
+//
+// Top processes change from scrape to scrape, so to keep the counters monotonic CPU usage is accounted to process name
+// only while it's in top. The rest goes to "other" bucket, so the sum of all counters stays equal to the service CPU
+// usage (except the time consumed by processes which exited between scrapes).
+type Collector struct {
+	limit          int
+	clockFrequency float64
+	pageSize       uint64
+	groups         map[string]*groupState
+}
+
+type groupState struct {
+	processes map[int]processState
+	names     map[string]*nameState
+	other     uint64
+	collected bool
+}
+
+type nameState struct {
+	usage  uint64
+	missed int // Number of collections the name has been absent during
+}
+
+type processState struct {
+	name  string
+	usage uint64
+}
+
+type nameUsage struct {
+	name  string
+	usage uint64
+	rss   uint64
+}
+
+var _ cgroups.Collector = &Collector{}
+
+func NewCollector(limit int) (*Collector, error) {
+	clockFrequency, err := sysconf.Sysconf(sysconf.SC_CLK_TCK)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get SC_CLK_TCK value: %w", err)
+	}
+
+	return &Collector{
+		limit:          limit,
+		clockFrequency: float64(clockFrequency),
+		pageSize:       uint64(os.Getpagesize()),
+		groups:         make(map[string]*groupState),
+	}, nil
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- cpuUsageMetric
+	descs <- rssMetric
+}
+
+func (c *Collector) Pre() {
+	for _, state := range c.groups {
+		state.collected = false
+	}
+}
+
+func (c *Collector) Post(ctx context.Context) {
+	for group, state := range c.groups {
+		if !state.collected {
+			logging.L(ctx).Debugf("topprocs: %q group hasn't been collected. Dropping its state.", group)
+			delete(c.groups, group)
+		}
+	}
+}
+
+func (c *Collector) Collect(
+	ctx context.Context, service string, group *cgroups.Group, exclude []string, metrics chan<- prometheus.Metric,
+) (bool, error) {
+	pids, exists, err := group.AllPIDs(exclude)
+	if err != nil || !exists {
+		return exists, err
+	}
+
+	state, ok := c.groups[group.Name]
+	if !ok {
+		state = &groupState{
+			processes: make(map[int]processState),
+			names:     make(map[string]*nameState),
+		}
+		c.groups[group.Name] = state
+	}
+
+	processes := make(map[int]processState, len(pids))
+	usageByName := make(map[string]*nameUsage)
+
+	taskErrors := cgroupsutil.NewTaskErrors("topprocs")
+
+	for _, pid := range pids {
+		stat, exists, err := readStat(pid)
+		if err != nil {
+			taskErrors.Failed(ctx, pid, err)
+			continue
+		} else if !exists {
+			continue
+		}
+		taskErrors.Read()
+
+		process := processState{
+			name:  stat.Name,
+			usage: stat.UserTime + stat.SystemTime,
+		}
+		processes[pid] = process
+
+		// New processes (including the ones with reused PID) are accounted with all their usage
+		usage := process.usage
+		if prev, ok := state.processes[pid]; ok && prev.name == process.name && prev.usage <= process.usage {
+			usage -= prev.usage
+		}
+
+		name, ok := usageByName[process.name]
+		if !ok {
+			name = &nameUsage{name: process.name}
+			usageByName[process.name] = name
+		}
+		name.usage += usage
+		name.rss += stat.RSS * c.pageSize
+	}
+
+	if err := taskErrors.Err(); err != nil {
+		return true, err
+	}
+
+	names := make([]*nameUsage, 0, len(usageByName))
+	for _, usage := range usageByName {
+		names = append(names, usage)
+	}
+
+	cpuTop, cpuOther := c.top(names, func(usage *nameUsage) uint64 { return usage.usage })
+	rssTop, rssOther := c.top(names, func(usage *nameUsage) uint64 { return usage.rss })
+
+	// Commit the state only after successful collection. Usage of the dropped names is moved to "other" bucket to keep
+	// the sum of all counters monotonic.
+	for name, prev := range state.names {
+		if _, ok := usageByName[name]; ok {
+			prev.missed = 0
+		} else if prev.missed++; prev.missed > nameGracePeriod {
+			state.other += prev.usage
+			delete(state.names, name)
+		}
+	}
+	for _, usage := range cpuTop {
+		name, ok := state.names[usage.name]
+		if !ok {
+			name = &nameState{}
+			state.names[usage.name] = name
+		}
+		name.usage += usage.usage
+	}
+	state.other += cpuOther
+	state.processes = processes
+	state.collected = true
+
+	for _, usage := range cpuTop {
+		c.recordCPUUsage(ctx, service, usage.name, state.names[usage.name].usage, metrics)
+	}
+	c.recordCPUUsage(ctx, service, otherName, state.other, metrics)
+
+	for _, usage := range rssTop {
+		c.recordRSS(ctx, service, usage.name, usage.rss, metrics)
+	}
+	c.recordRSS(ctx, service, otherName, rssOther, metrics)
+
+	return true, nil
+}
+
+func readStat(pid int) (procfs.Stat, bool, error) {
+	data, exists, err := procfs.ReadFile(pid, "stat")
+	if err != nil || !exists {
+		return procfs.Stat{}, exists, err
+	}
+
+	stat, ok := procfs.ParseStat(data)
+	if !ok {
+		return procfs.Stat{}, false, fmt.Errorf("/proc/%d/stat has an unexpected data: %q", pid, string(data))
+	}
+
+	return stat, true, nil
+}
+
+// Returns top processes by the specified key and the sum of the others
+func (c *Collector) top(names []*nameUsage, key func(usage *nameUsage) uint64) ([]*nameUsage, uint64) {
+	slices.SortFunc(names, func(a, b *nameUsage) int {
+		if result := cmp.Compare(key(b), key(a)); result != 0 {
+			return result
+		}
+		return cmp.Compare(a.name, b.name)
+	})
+
+	var top []*nameUsage
+	var other uint64
+
+	for _, usage := range names {
+		// Also protect ourselves from real processes named as our bucket
+		if len(top) < c.limit && usage.name != otherName {
+			top = append(top, usage)
+		} else {
+			other += key(usage)
+		}
+	}
+
+	return top, other
+}
+
+func (c *Collector) recordCPUUsage(
+	ctx context.Context, service string, name string, ticks uint64, metrics chan<- prometheus.Metric,
+) {
+	usage := float64(ticks) / c.clockFrequency
+	logging.L(ctx).Debugf("* %s: top processes: %s: cpu=%v", service, name, usage)
+	metrics <- prometheus.MustNewConstMetric(cpuUsageMetric, prometheus.CounterValue, usage, service, name)
+}
+
+func (c *Collector) recordRSS(
+	ctx context.Context, service string, name string, rss uint64, metrics chan<- prometheus.Metric,
+) {
+	logging.L(ctx).Debugf("* %s: top processes: %s: rss=%d", service, name, rss)
+	metrics <- prometheus.MustNewConstMetric(rssMetric, prometheus.GaugeValue, float64(rss), service, name)
+}
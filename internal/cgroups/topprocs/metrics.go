@@ -0,0 +1,14 @@
+package topprocs
+
+import (
+	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/metrics"
+)
+
+var metricBuilder = metrics.MakeDescBuilder("services_top_processes").WithLabels("service")
+
+var cpuUsageMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"cpu_usage", "CPU time consumed by the service top processes while they've been in top.", []string{"process"}))
+
+var rssMetric = cgroups.SumAggregated(metricBuilder.Build(
+	"rss", "Resident set size of the service top processes.", []string{"process"}))
@@ -13,8 +13,11 @@ type cachingResolver struct {
 
 var _ Resolver = &cachingResolver{}
 
+// All containers are resolved during each collection, so the cache must fit all running containers of the host
+const cacheSize = 1000
+
 func newCachingResolver(resolver Resolver) Resolver {
-	cache, err := lru.New[string, Container](10)
+	cache, err := lru.New[string, Container](cacheSize)
 	if err != nil {
 		panic(err)
 	}
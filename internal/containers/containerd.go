@@ -0,0 +1,114 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	containersapi "github.com/containerd/containerd/api/services/containers/v1"
+	namespacesapi "github.com/containerd/containerd/api/services/namespaces/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const DefaultContainerdEndpoint = "/run/containerd/containerd.sock"
+
+// Labels set by nerdctl on its containers
+const (
+	nerdctlNameLabel       = "nerdctl/name"
+	nerdctlAutoRemoveLabel = "nerdctl/auto-remove"
+)
+
+// containerd selects namespace by this gRPC metadata key
+const containerdNamespaceKey = "containerd-namespace"
+
+type containerdResolver struct {
+	endpoint string
+
+	lock       sync.Mutex
+	connection *grpc.ClientConn
+}
+
+var _ Resolver = &containerdResolver{}
+
+// NewContainerdResolver returns a resolver of containers created by nerdctl directly on containerd. Containers may
+// reside in any containerd namespace.
+func NewContainerdResolver(endpoint string) Resolver {
+	return newCachingResolver(&containerdResolver{endpoint: endpoint})
+}
+
+func (r *containerdResolver) Resolve(ctx context.Context, id string) (Container, error) {
+	connection, err := r.getConnection()
+	if err != nil {
+		return Container{}, err
+	}
+
+	namespaces, err := namespacesapi.NewNamespacesClient(connection).List(ctx, &namespacesapi.ListNamespacesRequest{})
+	if err != nil {
+		return Container{}, err
+	}
+
+	client := containersapi.NewContainersClient(connection)
+
+	for _, namespace := range namespaces.Namespaces {
+		namespaceCtx := metadata.AppendToOutgoingContext(ctx, containerdNamespaceKey, namespace.Name)
+
+		response, err := client.Get(namespaceCtx, &containersapi.GetContainerRequest{ID: id})
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				continue
+			}
+			return Container{}, err
+		}
+
+		labels := response.Container.Labels
+
+		name := labels[nerdctlNameLabel]
+		if name == "" {
+			name = id
+		}
+
+		container := Container{
+			ID:        response.Container.ID,
+			Name:      name,
+			Temporary: labels[nerdctlAutoRemoveLabel] == "true",
+		}
+		container.setImage(response.Container.Image)
+
+		return container, nil
+	}
+
+	return Container{}, fmt.Errorf("Unable to find %q container", id)
+}
+
+func (r *containerdResolver) getConnection() (*grpc.ClientConn, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.connection == nil {
+		connection, err := grpc.NewClient("unix://"+r.endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		r.connection = connection
+	}
+
+	return r.connection, nil
+}
+
+func (r *containerdResolver) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.connection != nil {
+		if err := r.connection.Close(); err != nil {
+			return err
+		}
+		r.connection = nil
+	}
+
+	return nil
+}
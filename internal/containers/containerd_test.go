@@ -0,0 +1,96 @@
+package containers
+
+import (
+	"context"
+	"net"
+	"path"
+	"testing"
+
+	containersapi "github.com/containerd/containerd/api/services/containers/v1"
+	namespacesapi "github.com/containerd/containerd/api/services/namespaces/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeNamespacesServer struct {
+	namespacesapi.UnimplementedNamespacesServer
+	namespaces []string
+}
+
+func (s *fakeNamespacesServer) List(
+	ctx context.Context, request *namespacesapi.ListNamespacesRequest,
+) (*namespacesapi.ListNamespacesResponse, error) {
+	var response namespacesapi.ListNamespacesResponse
+	for _, name := range s.namespaces {
+		response.Namespaces = append(response.Namespaces, &namespacesapi.Namespace{Name: name})
+	}
+	return &response, nil
+}
+
+type fakeContainersServer struct {
+	containersapi.UnimplementedContainersServer
+	containers map[string]map[string]*containersapi.Container
+}
+
+func (s *fakeContainersServer) Get(
+	ctx context.Context, request *containersapi.GetContainerRequest,
+) (*containersapi.GetContainerResponse, error) {
+	var namespace string
+	if values := metadata.ValueFromIncomingContext(ctx, "containerd-namespace"); len(values) == 1 {
+		namespace = values[0]
+	}
+
+	container, ok := s.containers[namespace][request.ID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "container %q in namespace %q: not found", request.ID, namespace)
+	}
+
+	return &containersapi.GetContainerResponse{Container: container}, nil
+}
+
+func TestContainerdResolver(t *testing.T) {
+	ctx := context.Background()
+	socketPath := path.Join(t.TempDir(), "containerd.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	namespacesapi.RegisterNamespacesServer(server, &fakeNamespacesServer{namespaces: []string{"default", "k8s.io"}})
+	containersapi.RegisterContainersServer(server, &fakeContainersServer{
+		containers: map[string]map[string]*containersapi.Container{
+			"default": {
+				"5c1e": {ID: "5c1e", Image: "quay.io/minio/minio:RELEASE.2025-09-07T16-13-09Z", Labels: map[string]string{"nerdctl/name": "minio"}},
+				"7d2f": {ID: "7d2f", Image: "alpine", Labels: map[string]string{"nerdctl/name": "alpine-7d2f0", "nerdctl/auto-remove": "true"}},
+			},
+			"k8s.io": {
+				"8e3a": {ID: "8e3a"},
+			},
+		},
+	})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	resolver := NewContainerdResolver(socketPath)
+	defer func() {
+		require.NoError(t, resolver.Close())
+	}()
+
+	for id, expected := range map[string]Container{
+		"5c1e": {ID: "5c1e", Name: "minio", Image: "quay.io/minio/minio", ImageTag: "RELEASE.2025-09-07T16-13-09Z"},
+		"7d2f": {ID: "7d2f", Name: "alpine-7d2f0", Temporary: true, Image: "alpine", ImageTag: "latest"},
+		"8e3a": {ID: "8e3a", Name: "8e3a"},
+	} {
+		container, err := resolver.Resolve(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, expected, container)
+	}
+
+	_, err = resolver.Resolve(ctx, "0000")
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// DefaultCRIEndpoints are the sockets of the popular CRI runtimes (containerd, k3s' embedded containerd, CRI-O)
+var DefaultCRIEndpoints = []string{
+	"/run/containerd/containerd.sock",
+	"/run/k3s/containerd/containerd.sock",
+	"/run/crio/crio.sock",
+}
+
+// Labels set by kubelet on all containers
+const (
+	criPodNamespaceLabel = "io.kubernetes.pod.namespace"
+	criPodNameLabel      = "io.kubernetes.pod.name"
+	criContainerLabel    = "io.kubernetes.container.name"
+)
+
+type criResolver struct {
+	endpoints []string
+
+	lock       sync.Mutex
+	connection *grpc.ClientConn
+	client     runtimeapi.RuntimeServiceClient
+}
+
+var _ Resolver = &criResolver{}
+
+// NewCRIResolver returns a resolver of Kubernetes containers, which resolves them to namespace/pod/container names
+// using the first available CRI runtime socket.
+func NewCRIResolver(endpoints []string) Resolver {
+	return newCachingResolver(&criResolver{endpoints: endpoints})
+}
+
+func (r *criResolver) Resolve(ctx context.Context, id string) (Container, error) {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return Container{}, err
+	}
+
+	response, err := client.ContainerStatus(ctx, &runtimeapi.ContainerStatusRequest{ContainerId: id})
+	if err == nil {
+		container := response.GetStatus()
+		labels := container.GetLabels()
+
+		name := labels[criContainerLabel]
+		if name == "" {
+			name = container.GetMetadata().GetName()
+		}
+
+		result := Container{
+			ID:   container.GetId(),
+			Name: fmt.Sprintf("%s/%s/%s", labels[criPodNamespaceLabel], labels[criPodNameLabel], name),
+		}
+		result.setImage(container.GetImage().GetImage())
+
+		return result, nil
+	} else if status.Code(err) != codes.NotFound {
+		return Container{}, err
+	}
+
+	// Each pod has a sandbox (pause) container which isn't listed as a regular container
+	sandboxResponse, err := client.PodSandboxStatus(ctx, &runtimeapi.PodSandboxStatusRequest{PodSandboxId: id})
+	if err != nil {
+		return Container{}, err
+	}
+
+	sandbox := sandboxResponse.GetStatus()
+	pod := sandbox.GetMetadata()
+
+	return Container{
+		ID:   sandbox.GetId(),
+		Name: fmt.Sprintf("%s/%s/sandbox", pod.GetNamespace(), pod.GetName()),
+	}, nil
+}
+
+// Returns the client of the first existing CRI runtime socket which serves CRI API. containerd socket may exist, but
+// have CRI plugin disabled (Unimplemented), and stale sockets of stopped runtimes refuse connections (Unavailable), so
+// each endpoint is probed before it's chosen.
+func (r *criResolver) getClient(ctx context.Context) (runtimeapi.RuntimeServiceClient, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.client != nil {
+		return r.client, nil
+	}
+
+	for _, endpoint := range r.endpoints {
+		if _, err := os.Stat(endpoint); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		connection, err := grpc.NewClient("unix://"+endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+
+		client := runtimeapi.NewRuntimeServiceClient(connection)
+
+		if _, err := client.Version(ctx, &runtimeapi.VersionRequest{}); err != nil {
+			_ = connection.Close()
+
+			if code := status.Code(err); code == codes.Unimplemented || code == codes.Unavailable {
+				continue
+			}
+			return nil, fmt.Errorf("%s: %w", endpoint, err)
+		}
+
+		r.connection = connection
+		r.client = client
+
+		return r.client, nil
+	}
+
+	return nil, errors.New("Unable to find CRI runtime socket")
+}
+
+func (r *criResolver) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.connection != nil {
+		if err := r.connection.Close(); err != nil {
+			return err
+		}
+		r.connection = nil
+		r.client = nil
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package containers
+
+import (
+	"context"
+	"net"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+type fakeCRIServer struct {
+	runtimeapi.UnimplementedRuntimeServiceServer
+	containers map[string]*runtimeapi.ContainerStatus
+	sandboxes  map[string]*runtimeapi.PodSandboxStatus
+}
+
+func (s *fakeCRIServer) ContainerStatus(
+	ctx context.Context, request *runtimeapi.ContainerStatusRequest,
+) (*runtimeapi.ContainerStatusResponse, error) {
+	container, ok := s.containers[request.ContainerId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "container %q not found", request.ContainerId)
+	}
+	return &runtimeapi.ContainerStatusResponse{Status: container}, nil
+}
+
+func (s *fakeCRIServer) PodSandboxStatus(
+	ctx context.Context, request *runtimeapi.PodSandboxStatusRequest,
+) (*runtimeapi.PodSandboxStatusResponse, error) {
+	sandbox, ok := s.sandboxes[request.PodSandboxId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "pod sandbox %q not found", request.PodSandboxId)
+	}
+	return &runtimeapi.PodSandboxStatusResponse{Status: sandbox}, nil
+}
+
+func (s *fakeCRIServer) Version(
+	ctx context.Context, request *runtimeapi.VersionRequest,
+) (*runtimeapi.VersionResponse, error) {
+	return &runtimeapi.VersionResponse{RuntimeName: "fake"}, nil
+}
+
+func TestCRIResolver(t *testing.T) {
+	ctx := context.Background()
+	socketPath := path.Join(t.TempDir(), "cri.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	runtimeapi.RegisterRuntimeServiceServer(server, &fakeCRIServer{
+		containers: map[string]*runtimeapi.ContainerStatus{
+			"3f2a": {
+				Id:       "3f2a",
+				Metadata: &runtimeapi.ContainerMetadata{Name: "nginx"},
+				Image:    &runtimeapi.ImageSpec{Image: "docker.io/library/nginx:1.25"},
+				Labels: map[string]string{
+					"io.kubernetes.pod.namespace":  "default",
+					"io.kubernetes.pod.name":       "web-7c5ddbdf54-x2x8k",
+					"io.kubernetes.container.name": "nginx",
+				},
+			},
+		},
+		sandboxes: map[string]*runtimeapi.PodSandboxStatus{
+			"9b1c": {
+				Id:       "9b1c",
+				Metadata: &runtimeapi.PodSandboxMetadata{Name: "web-7c5ddbdf54-x2x8k", Namespace: "default"},
+			},
+		},
+	})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	// containerd socket with disabled CRI plugin
+	disabledSocketPath := path.Join(t.TempDir(), "containerd.sock")
+
+	disabledListener, err := net.Listen("unix", disabledSocketPath)
+	require.NoError(t, err)
+
+	disabledServer := grpc.NewServer()
+	go func() {
+		_ = disabledServer.Serve(disabledListener)
+	}()
+	defer disabledServer.Stop()
+
+	resolver := NewCRIResolver([]string{path.Join(t.TempDir(), "missing.sock"), disabledSocketPath, socketPath})
+	defer func() {
+		require.NoError(t, resolver.Close())
+	}()
+
+	container, err := resolver.Resolve(ctx, "3f2a")
+	require.NoError(t, err)
+	require.Equal(t, Container{
+		ID:       "3f2a",
+		Name:     "default/web-7c5ddbdf54-x2x8k/nginx",
+		Image:    "docker.io/library/nginx",
+		ImageTag: "1.25",
+	}, container)
+
+	container, err = resolver.Resolve(ctx, "9b1c")
+	require.NoError(t, err)
+	require.Equal(t, Container{ID: "9b1c", Name: "default/web-7c5ddbdf54-x2x8k/sandbox"}, container)
+
+	_, err = resolver.Resolve(ctx, "0000")
+	require.Error(t, err)
+}
+
+func TestCRIResolverWithoutRuntime(t *testing.T) {
+	resolver := NewCRIResolver([]string{path.Join(t.TempDir(), "missing.sock")})
+	defer func() {
+		require.NoError(t, resolver.Close())
+	}()
+
+	_, err := resolver.Resolve(context.Background(), "3f2a")
+	require.Error(t, err)
+}
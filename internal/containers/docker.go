@@ -2,6 +2,7 @@ package containers
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"sync"
 
@@ -9,6 +10,8 @@ import (
 )
 
 type dockerResolver struct {
+	options []client.Opt
+
 	lock   sync.Mutex
 	client *client.Client
 }
@@ -19,6 +22,16 @@ func NewDockerResolver() Resolver {
 	return newCachingResolver(&dockerResolver{})
 }
 
+// NewUserDockerResolver returns a resolver of rootless Docker containers which connects to Docker socket of the
+// container owner ($XDG_RUNTIME_DIR/docker.sock).
+func NewUserDockerResolver() UserResolver {
+	return newUserResolver(func(uid int) Resolver {
+		return newCachingResolver(&dockerResolver{
+			options: []client.Opt{client.WithHost(fmt.Sprintf("unix:///run/user/%d/docker.sock", uid))},
+		})
+	})
+}
+
 func (r *dockerResolver) Resolve(ctx context.Context, id string) (Container, error) {
 	cli, err := r.getClient()
 	if err != nil {
@@ -35,10 +48,17 @@ func (r *dockerResolver) Resolve(ctx context.Context, id string) (Container, err
 		temporary = hostConfig.AutoRemove
 	}
 
-	return Container{
+	container := Container{
+		ID:        info.ID,
 		Name:      strings.TrimLeft(info.Name, "/"),
 		Temporary: temporary,
-	}, nil
+	}
+	if config := info.Config; config != nil {
+		container.setImage(config.Image)
+		container.setComposeLabels(config.Labels)
+	}
+
+	return container, nil
 }
 
 func (r *dockerResolver) getClient() (*client.Client, error) {
@@ -48,7 +68,7 @@ func (r *dockerResolver) getClient() (*client.Client, error) {
 	if r.client == nil {
 		var err error
 
-		r.client, err = client.NewClientWithOpts()
+		r.client, err = client.NewClientWithOpts(r.options...)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,33 @@
+package containers
+
+import (
+	"regexp"
+	"strings"
+)
+
+var imageIDRegex = regexp.MustCompile(`^(?:sha256:)?[0-9a-f]{64}$`)
+
+// parseImage splits image reference into repository and tag. Digests are stripped and references by image ID produce
+// empty values.
+func parseImage(reference string) (string, string) {
+	if reference == "" || imageIDRegex.MatchString(reference) {
+		return "", ""
+	}
+
+	repository, _, hasDigest := strings.Cut(reference, "@")
+
+	// The port of registry host also follows a colon, so look for the tag only in the last path component
+	if index := strings.LastIndexByte(repository, ':'); index > strings.LastIndexByte(repository, '/') {
+		return repository[:index], repository[index+1:]
+	}
+
+	if hasDigest {
+		return repository, ""
+	}
+
+	return repository, "latest"
+}
+
+func (c *Container) setImage(reference string) {
+	c.Image, c.ImageTag = parseImage(reference)
+}
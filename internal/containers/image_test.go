@@ -0,0 +1,30 @@
+package containers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImage(t *testing.T) {
+	for _, testCase := range []struct {
+		reference  string
+		repository string
+		tag        string
+	}{
+		{"", "", ""},
+		{"nginx", "nginx", "latest"},
+		{"nginx:1.25", "nginx", "1.25"},
+		{"docker.io/library/nextcloud:29-apache", "docker.io/library/nextcloud", "29-apache"},
+		{"localhost:5000/backup", "localhost:5000/backup", "latest"},
+		{"localhost:5000/backup:v2", "localhost:5000/backup", "v2"},
+		{"ghcr.io/owner/app@sha256:4d8e4b1a0b6a5f3c1e8e1c7b0f2c9d6e5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d", "ghcr.io/owner/app", ""},
+		{"ghcr.io/owner/app:v1@sha256:4d8e4b1a0b6a5f3c1e8e1c7b0f2c9d6e5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d", "ghcr.io/owner/app", "v1"},
+		{"sha256:4d8e4b1a0b6a5f3c1e8e1c7b0f2c9d6e5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d", "", ""},
+		{"4d8e4b1a0b6a5f3c1e8e1c7b0f2c9d6e5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d", "", ""},
+	} {
+		repository, tag := parseImage(testCase.reference)
+		require.Equal(t, testCase.repository, repository, testCase.reference)
+		require.Equal(t, testCase.tag, tag, testCase.reference)
+	}
+}
@@ -24,3 +24,9 @@ func (r *resolverMock) Resolve(ctx context.Context, id string) (Container, error
 func (r *resolverMock) Close() error {
 	return nil
 }
+
+func NewUserResolverMock(containers map[int]map[string]Container) UserResolver {
+	return newUserResolver(func(uid int) Resolver {
+		return NewResolverMock(containers[uid])
+	})
+}
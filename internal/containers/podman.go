@@ -2,14 +2,18 @@ package containers
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/containers/podman/v5/pkg/bindings"
 	"github.com/containers/podman/v5/pkg/bindings/containers"
+	"github.com/containers/podman/v5/pkg/bindings/pods"
 	"github.com/samber/mo"
 )
 
 type podmanResolver struct {
+	url string
+
 	lock          sync.Mutex
 	clientContext mo.Option[context.Context]
 }
@@ -17,7 +21,19 @@ type podmanResolver struct {
 var _ Resolver = &podmanResolver{}
 
 func NewPodmanResolver() Resolver {
-	return newCachingResolver(&podmanResolver{})
+	return newPodmanResolver("unix:///run/podman/podman.sock")
+}
+
+// NewUserPodmanResolver returns a resolver of rootless Podman containers which connects to Podman socket of the
+// container owner.
+func NewUserPodmanResolver() UserResolver {
+	return newUserResolver(func(uid int) Resolver {
+		return newPodmanResolver(fmt.Sprintf("unix:///run/user/%d/podman/podman.sock", uid))
+	})
+}
+
+func newPodmanResolver(url string) Resolver {
+	return newCachingResolver(&podmanResolver{url: url})
 }
 
 func (r *podmanResolver) Resolve(_ context.Context, id string) (Container, error) {
@@ -31,17 +47,39 @@ func (r *podmanResolver) Resolve(_ context.Context, id string) (Container, error
 		return Container{}, err
 	}
 
+	var systemdUnit string
+	if config := info.Config; config != nil {
+		systemdUnit = config.Labels["PODMAN_SYSTEMD_UNIT"]
+	}
+
 	var temporary bool
-	if config := info.Config; config == nil || config.Labels["PODMAN_SYSTEMD_UNIT"] == "" {
+	if systemdUnit == "" {
 		if hostConfig := info.HostConfig; hostConfig != nil {
 			temporary = hostConfig.AutoRemove
 		}
 	}
 
-	return Container{
-		Name:      info.Name,
-		Temporary: temporary,
-	}, nil
+	container := Container{
+		ID:          info.ID,
+		Name:        info.Name,
+		Temporary:   temporary,
+		SystemdUnit: systemdUnit,
+	}
+	container.setImage(info.ImageName)
+
+	if info.Pod != "" {
+		pod, err := pods.Inspect(clientContext, info.Pod, nil) //nolint:contextcheck
+		if err != nil {
+			return Container{}, err
+		}
+		container.Pod = pod.Name
+	}
+
+	if config := info.Config; config != nil {
+		container.setComposeLabels(config.Labels)
+	}
+
+	return container, nil
 }
 
 func (r *podmanResolver) getClientContext() (context.Context, error) {
@@ -53,7 +91,7 @@ func (r *podmanResolver) getClientContext() (context.Context, error) {
 		return clientContext, nil
 	}
 
-	clientContext, err := bindings.NewConnection(context.Background(), "unix:///run/podman/podman.sock")
+	clientContext, err := bindings.NewConnection(context.Background(), r.url)
 	if err != nil {
 		return nil, err
 	}
@@ -5,11 +5,39 @@ import (
 )
 
 type Container struct {
+	ID        string
 	Name      string
 	Temporary bool // Temporary containers have auto-generated names
+
+	Image       string // Image repository
+	ImageTag    string
+	SystemdUnit string // systemd unit which manages the container
+
+	Pod            string // Name of the pod the container belongs to
+	ComposeProject string
+	ComposeService string
+}
+
+// Labels set by Docker Compose and podman-compose
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
+func (c *Container) setComposeLabels(labels map[string]string) {
+	if project, service := labels[composeProjectLabel], labels[composeServiceLabel]; project != "" && service != "" {
+		c.ComposeProject = project
+		c.ComposeService = service
+	}
 }
 
 type Resolver interface {
 	Resolve(ctx context.Context, id string) (Container, error)
 	Close() error
 }
+
+// UserResolver resolves containers of rootless container runtimes running in user sessions.
+type UserResolver interface {
+	Resolve(ctx context.Context, uid int, id string) (Container, error)
+	Close() error
+}
@@ -0,0 +1,57 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// userResolver lazily creates a separate resolver for each user.
+type userResolver struct {
+	newResolver func(uid int) Resolver
+
+	lock      sync.Mutex
+	resolvers map[int]Resolver
+}
+
+var _ UserResolver = &userResolver{}
+
+func newUserResolver(newResolver func(uid int) Resolver) UserResolver {
+	return &userResolver{
+		newResolver: newResolver,
+		resolvers:   make(map[int]Resolver),
+	}
+}
+
+func (r *userResolver) Resolve(ctx context.Context, uid int, id string) (Container, error) {
+	return r.getResolver(uid).Resolve(ctx, id)
+}
+
+func (r *userResolver) getResolver(uid int) Resolver {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	resolver, ok := r.resolvers[uid]
+	if !ok {
+		resolver = r.newResolver(uid)
+		r.resolvers[uid] = resolver
+	}
+
+	return resolver
+}
+
+func (r *userResolver) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var errs []error
+
+	for uid, resolver := range r.resolvers {
+		if err := resolver.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(r.resolvers, uid)
+	}
+
+	return errors.Join(errs...)
+}
@@ -1,15 +1,10 @@
 package kernelprocs
 
 import (
-	"bytes"
 	"context"
-	"errors"
 	"fmt"
-	"os"
-	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 
 	logging "github.com/KonishchevDmitry/go-easy-logging"
 	"github.com/prometheus/client_golang/prometheus"
@@ -17,6 +12,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/KonishchevDmitry/server-metrics/internal/cgroups"
+	"github.com/KonishchevDmitry/server-metrics/internal/procfs"
 )
 
 type Collector struct {
@@ -78,64 +74,46 @@ func (c *Collector) observe(ctx context.Context, metrics chan<- prometheus.Metri
 	kworkersUsage := c.kworkersUsage
 
 	for _, pid := range pids {
-		path := fmt.Sprintf("/proc/%d/stat", pid)
-
-		data, err := os.ReadFile(path)
+		data, exists, err := procfs.ReadFile(pid, "stat")
 		if err != nil {
-			var errno syscall.Errno
-			if !errors.As(err, &errno) {
-				return err
-			}
-
-			switch errno {
-			case syscall.ESRCH:
-			case syscall.ENOENT:
-				if _, err := os.Stat("/proc/self/stat"); err != nil {
-					if os.IsNotExist(err) {
-						return errors.New("/proc is not mounted")
-					}
-					return err
-				}
-			default:
-				return err
-			}
-
+			return err
+		} else if !exists {
 			continue
 		}
 
-		stat, ok := parseProcStat(data)
+		stat, ok := procfs.ParseStat(data)
 		if !ok {
-			logging.L(ctx).Errorf("%q has an unexpected data: %q.", path, string(data))
+			logging.L(ctx).Errorf("/proc/%d/stat has an unexpected data: %q.", pid, string(data))
 			continue
 		}
 
 		// kworker processes constantly change their names displaying currently processing task, so we can't collect
 		// they by name.
-		if strings.HasPrefix(stat.name, "kworker/") {
-			kworkers[pid] = stat.usage
+		if strings.HasPrefix(stat.Name, "kworker/") {
+			kworkers[pid] = stat.SystemTime
 
 			if prevUsage, ok := c.kworkers[pid]; ok {
-				if stat.usage < prevUsage {
+				if stat.SystemTime < prevUsage {
 					logging.L(ctx).Errorf("Got CPU usage decrease for #%d kworker.", pid)
 				} else {
-					kworkersUsage += stat.usage - prevUsage
+					kworkersUsage += stat.SystemTime - prevUsage
 				}
 			}
 
 			continue
-		} else if stat.usage == 0 {
+		} else if stat.SystemTime == 0 {
 			continue
 		}
 
-		if _, ok := names[stat.name]; ok {
-			logging.L(ctx).Errorf("Got a duplicated kernel process name: %q.", stat.name)
+		if _, ok := names[stat.Name]; ok {
+			logging.L(ctx).Errorf("Got a duplicated kernel process name: %q.", stat.Name)
 			continue
 		}
-		names[stat.name] = struct{}{}
+		names[stat.Name] = struct{}{}
 
-		usage := float64(stat.usage) / c.clockFrequency
-		logging.L(ctx).Debugf("* #%d (%s): %v", pid, stat.name, usage)
-		metrics <- prometheus.MustNewConstMetric(cpuUsageMetric, prometheus.CounterValue, usage, stat.name)
+		usage := float64(stat.SystemTime) / c.clockFrequency
+		logging.L(ctx).Debugf("* #%d (%s): %v", pid, stat.Name, usage)
+		metrics <- prometheus.MustNewConstMetric(cpuUsageMetric, prometheus.CounterValue, usage, stat.Name)
 	}
 
 	c.kworkers = kworkers
@@ -147,36 +125,3 @@ func (c *Collector) observe(ctx context.Context, metrics chan<- prometheus.Metri
 
 	return nil
 }
-
-type procStat struct {
-	name  string
-	usage uint64
-}
-
-// https://man7.org/linux/man-pages/man5/proc_pid_stat.5.html
-func parseProcStat(data []byte) (procStat, bool) {
-	nameStart := bytes.IndexByte(data, '(')
-	nameEnd := bytes.LastIndexByte(data, ')')
-
-	if nameStart == -1 || nameEnd < nameStart {
-		return procStat{}, false
-	}
-
-	const statShift = 2
-	statValues := bytes.Split(data[nameEnd:], []byte(" "))
-
-	stimePos := 15 - statShift
-	if len(statValues) <= stimePos {
-		return procStat{}, false
-	}
-
-	stime, err := strconv.ParseUint(string(statValues[stimePos]), 10, 64)
-	if err != nil {
-		return procStat{}, false
-	}
-
-	return procStat{
-		name:  string(data[nameStart+1 : nameEnd]),
-		usage: stime,
-	}, true
-}
@@ -0,0 +1,61 @@
+package procfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// ReadFile reads /proc/$pid/$name file. Returns false if the process has already exited.
+func ReadFile(pid int, name string) ([]byte, bool, error) {
+	data, err := os.ReadFile(path(pid, name))
+	if err != nil {
+		return nil, false, mapError(err)
+	}
+	return data, true, nil
+}
+
+// Readlink reads /proc/$pid/$name link. Returns false if the process has already exited.
+func Readlink(pid int, name string) (string, bool, error) {
+	target, err := os.Readlink(path(pid, name))
+	if err != nil {
+		return "", false, mapError(err)
+	}
+	return target, true, nil
+}
+
+// ReadDir reads /proc/$pid/$name directory. Returns false if the process has already exited.
+func ReadDir(pid int, name string) ([]os.DirEntry, bool, error) {
+	entries, err := os.ReadDir(path(pid, name))
+	if err != nil {
+		return nil, false, mapError(err)
+	}
+	return entries, true, nil
+}
+
+func path(pid int, name string) string {
+	return fmt.Sprintf("/proc/%d/%s", pid, name)
+}
+
+func mapError(err error) error {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return err
+	}
+
+	switch errno {
+	case syscall.ESRCH:
+		return nil
+	case syscall.ENOENT:
+		if _, err := os.Stat("/proc/self/stat"); err != nil {
+			if os.IsNotExist(err) {
+				return errors.New("/proc is not mounted")
+			}
+			return err
+		}
+		return nil
+	default:
+		return err
+	}
+}
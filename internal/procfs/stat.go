@@ -0,0 +1,71 @@
+package procfs
+
+import (
+	"bytes"
+	"strconv"
+)
+
+type Stat struct {
+	Name      string
+	ParentPID int
+
+	// CPU time in clock ticks
+	UserTime   uint64
+	SystemTime uint64
+
+	// Resident set size in pages
+	RSS uint64
+}
+
+// ParseStat parses /proc/$pid/stat file.
+//
+// https://man7.org/linux/man-pages/man5/proc_pid_stat.5.html
+func ParseStat(data []byte) (Stat, bool) {
+	nameStart := bytes.IndexByte(data, '(')
+	nameEnd := bytes.LastIndexByte(data, ')')
+
+	if nameStart == -1 || nameEnd < nameStart {
+		return Stat{}, false
+	}
+
+	const statShift = 2
+	statValues := bytes.Split(bytes.TrimSuffix(data[nameEnd:], []byte("\n")), []byte(" "))
+
+	parse := func(pos int) (uint64, bool) {
+		pos -= statShift
+		if len(statValues) <= pos {
+			return 0, false
+		}
+
+		value, err := strconv.ParseUint(string(statValues[pos]), 10, 64)
+		return value, err == nil
+	}
+
+	ppid, ok := parse(4)
+	if !ok {
+		return Stat{}, false
+	}
+
+	utime, ok := parse(14)
+	if !ok {
+		return Stat{}, false
+	}
+
+	stime, ok := parse(15)
+	if !ok {
+		return Stat{}, false
+	}
+
+	rss, ok := parse(24)
+	if !ok {
+		return Stat{}, false
+	}
+
+	return Stat{
+		Name:       string(data[nameStart+1 : nameEnd]),
+		ParentPID:  int(ppid),
+		UserTime:   utime,
+		SystemTime: stime,
+		RSS:        rss,
+	}, true
+}
@@ -0,0 +1,25 @@
+package procfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStat(t *testing.T) {
+	data := "1005 (tmux: server) S 1 1005 1005 0 -1 4194624 36370 2 0 0 1357 2484 0 0 20 0 1 0 2452 12816384 1430 " +
+		"18446744073709551615 1 1 0 0 0 0 0 528386 134433281 0 0 0 17 2 0 0 0 0 0 0 0 0 0 0 0 0 0\n"
+
+	stat, ok := ParseStat([]byte(data))
+	require.True(t, ok)
+	require.Equal(t, Stat{
+		Name:       "tmux: server",
+		ParentPID:  1,
+		UserTime:   1357,
+		SystemTime: 2484,
+		RSS:        1430,
+	}, stat)
+
+	_, ok = ParseStat([]byte("1005 (tmux: server) S 1 1005"))
+	require.False(t, ok)
+}